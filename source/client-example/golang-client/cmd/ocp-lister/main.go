@@ -1,71 +1,238 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/bryon/ocp-lister/internal/auth"
+	"github.com/bryon/ocp-lister/internal/cli"
 	"github.com/bryon/ocp-lister/internal/client"
+	"github.com/bryon/ocp-lister/internal/config"
 	"github.com/bryon/ocp-lister/internal/menu"
-	"github.com/bryon/ocp-lister/internal/objects/clusterrolebindings"
-	"github.com/bryon/ocp-lister/internal/objects/groups"
-	"github.com/bryon/ocp-lister/internal/objects/models"
-	"github.com/bryon/ocp-lister/internal/objects/projects"
-	"github.com/bryon/ocp-lister/internal/objects/users"
+	_ "github.com/bryon/ocp-lister/internal/objects"
+	"github.com/bryon/ocp-lister/internal/operation"
+	"github.com/bryon/ocp-lister/internal/session"
+	"github.com/bryon/ocp-lister/internal/tui"
+	"github.com/bryon/ocp-lister/internal/whoami"
 )
 
 func main() {
-	// Load authentication configuration from environment variables
-	authConfig, err := auth.LoadFromEnv()
+	// Load configuration from flags, environment and the config file
+	cfg, args, err := config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-		os.Exit(1)
+		os.Exit(2)
 	}
 
-	fmt.Printf("Connecting to OpenShift cluster at %s...\n", authConfig.Server)
+	// Run a single subcommand for scripts, otherwise start the interactive menu
+	if len(args) > 0 {
+		os.Exit(cli.Run(cfg, args))
+	}
 
-	// Create Kubernetes client
-	clientset, err := client.CreateClient(
-		authConfig.Server,
-		authConfig.Username,
-		authConfig.Password,
-	)
+	sess, err := connect(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Successfully authenticated!")
-
-	// Create main menu
-	mainMenu := menu.NewMenu("OpenShift Kubernetes Object Manager")
-	mainMenu.AddOption("A", "Projects")
-	mainMenu.AddOption("B", "Groups")
-	mainMenu.AddOption("C", "Users")
-	mainMenu.AddOption("D", "Cluster Role Bindings")
-	mainMenu.AddOption("E", "Model")
-	mainMenu.AddOption("X", "Exit")
-
-	// Main menu loop
-	for {
-		choice := mainMenu.DisplayAndGetChoice()
-
-		switch choice {
-		case "A":
-			projects.HandleCRUDMenu(clientset)
-		case "B":
-			groups.HandleCRUDMenu(clientset)
-		case "C":
-			users.HandleCRUDMenu(clientset)
-		case "D":
-			clusterrolebindings.HandleCRUDMenu(clientset)
-		case "E":
-			models.HandleModelMenu(clientset)
-		case "X":
-			fmt.Println("Exiting...")
-			os.Exit(0)
+	// Every cluster connected to during this run, so all of their tokens are revoked on exit
+	sessions := []*session.Session{sess}
+
+	// exit optionally revokes the tokens of every session and ends the program
+	exit := func(code int, revoke bool) {
+		if revoke {
+			logout(sessions)
+		}
+		fmt.Println("Exiting...")
+		os.Exit(code)
+	}
+
+	// Ctrl-C cancels the running operation; pressed again, or at a menu, it exits
+	operation.HandleInterrupts(func() { exit(130, cfg.Settings.RevokeOnExit) })
+
+	state := &menu.State{Session: sess}
+
+	// Show the current cluster and identity in every menu header
+	menu.SetHeader(func() string { return state.Session.Describe() })
+
+	// The object packages register their own entries (A-E); these act on the whole session
+	menu.Register(menu.Item{Key: "F", Title: "Impersonate user/groups", Order: 60, Action: func(state *menu.State) {
+		state.Session = handleImpersonate(state.Session)
+	}})
+	menu.Register(menu.Item{Key: "G", Title: "Switch cluster", Order: 70, Action: func(state *menu.State) {
+		if newCfg, newSess := handleSwitchCluster(cfg, state.Session); newSess != state.Session {
+			cfg, state.Session = newCfg, newSess
+			sessions = append(sessions, newSess)
+		}
+	}})
+	menu.Register(menu.Item{Key: "H", Title: "Logout (revoke token) and exit", Order: 80, Action: func(state *menu.State) {
+		exit(0, true)
+	}})
+	menu.Register(menu.Item{Key: "I", Title: "Who am I (identity, groups, tier, token expiry)", Order: 90, Action: func(state *menu.State) {
+		sess := state.Session
+		if err := sess.Run(func(ctx context.Context) error {
+			return whoami.HandleShow(ctx, sess)
+		}); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}})
+	menu.Register(menu.Item{Key: "J", Title: "Command line (history and tab completion)", Order: 100, Action: func(state *menu.State) {
+		cli.REPL(cfg.Settings, state.Session)
+	}})
+	menu.Register(menu.Item{Key: "K", Title: "Full-screen view", Order: 110, Action: func(state *menu.State) {
+		if err := tui.Run(state.Session); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}})
+	menu.Register(menu.Item{Key: "X", Title: "Exit", Order: 1000, Action: func(state *menu.State) {
+		exit(0, cfg.Settings.RevokeOnExit)
+	}})
+
+	// X exits the program, also at the end of piped input
+	menu.Main().Run(state)
+}
+
+// connect authenticates to the cluster described by the configuration
+func connect(cfg *config.Config) (*session.Session, error) {
+	if cfg.ProfileName != "" {
+		fmt.Printf("Using profile: %s\n", cfg.ProfileName)
+	}
+
+	if cfg.Auth.Server != "" {
+		fmt.Printf("Connecting to OpenShift cluster at %s (%s authentication)...\n", cfg.Auth.Server, cfg.Auth.Method)
+	} else {
+		fmt.Printf("Connecting to OpenShift cluster (%s authentication)...\n", cfg.Auth.Method)
+	}
+
+	// Authenticate once and share the clients with every handler
+	sess, err := session.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Successfully authenticated to %s as %s!\n", sess.Cluster, sess.User)
+	if cfg.Settings.ShowToken {
+		showToken(sess)
+	}
+	if who := sess.Impersonating(); who != "" {
+		fmt.Printf("Impersonating: %s\n", who)
+	}
+
+	return sess, nil
+}
+
+// showToken prints the bearer token of the session, only when explicitly requested
+func showToken(sess *session.Session) {
+	switch {
+	case sess.Tokens != nil && sess.Tokens.Current() != nil:
+		fmt.Printf("Bearer token: %s\n", sess.Tokens.Current().AccessToken)
+	case sess.Config.BearerToken != "":
+		fmt.Printf("Bearer token: %s\n", sess.Config.BearerToken)
+	default:
+		fmt.Println("No bearer token to show: the credentials come from a client certificate or token file.")
+	}
+}
+
+// logout revokes the tokens this tool obtained for each session and clears them
+// from the cache. Sessions that share a login (e.g. impersonated ones) are revoked once.
+func logout(sessions []*session.Session) {
+	seen := make(map[*client.TokenSource]bool)
+	for _, sess := range sessions {
+		if sess.Tokens != nil {
+			if seen[sess.Tokens] {
+				continue
+			}
+			seen[sess.Tokens] = true
+		}
+
+		revoked, err := sess.Logout()
+		switch {
+		case err != nil:
+			fmt.Printf("⚠️  Failed to revoke token for %s: %v\n", sess.Cluster, err)
+		case revoked:
+			fmt.Printf("✓ Logged out of %s (token revoked)\n", sess.Cluster)
 		default:
-			fmt.Printf("Unknown option: %s\n", choice)
+			fmt.Printf("Credentials for %s were not issued by this tool; nothing to revoke\n", sess.Cluster)
 		}
 	}
 }
+
+// handleSwitchCluster lets the user pick another config profile or kubeconfig
+// context and connects to it. The current session is kept if anything fails.
+func handleSwitchCluster(cfg *config.Config, sess *session.Session) (*config.Config, *session.Session) {
+	targets, err := cfg.Targets()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return cfg, sess
+	}
+	if len(targets) == 0 {
+		fmt.Println("No config profiles or kubeconfig contexts found.")
+		return cfg, sess
+	}
+
+	fmt.Printf("\nAvailable clusters (current: %s):\n\n", sess.Cluster)
+	for i, target := range targets {
+		fmt.Printf("%d. %s\n", i+1, target)
+	}
+	fmt.Println()
+
+	choice := menu.GetName("Select a cluster (or press Enter to cancel): ")
+	if choice == "" {
+		return cfg, sess
+	}
+	index, err := strconv.Atoi(choice)
+	if err != nil || index < 1 || index > len(targets) {
+		fmt.Printf("Invalid selection: %s\n", choice)
+		return cfg, sess
+	}
+
+	newCfg, err := cfg.ForTarget(targets[index-1])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return cfg, sess
+	}
+
+	newSess, err := connect(newCfg)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return cfg, sess
+	}
+
+	return newCfg, newSess
+}
+
+// handleImpersonate prompts for a user and groups to act as, so handlers show
+// exactly what that tenant would see. An empty user stops impersonating.
+func handleImpersonate(sess *session.Session) *session.Session {
+	if who := sess.Impersonating(); who != "" {
+		fmt.Printf("\nCurrently impersonating: %s\n", who)
+	}
+
+	user := menu.GetName("Enter user to impersonate (or press Enter to stop impersonating): ")
+	var groups []string
+	if user != "" {
+		groups = strings.FieldsFunc(menu.GetName("Enter groups, comma separated (optional): "), func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	}
+
+	impersonated, err := sess.Impersonate(user, groups)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return sess
+	}
+
+	if who := impersonated.Impersonating(); who != "" {
+		fmt.Printf("\n✓ Now impersonating: %s\n", who)
+	} else {
+		fmt.Println("\n✓ Stopped impersonating")
+	}
+
+	return impersonated
+}
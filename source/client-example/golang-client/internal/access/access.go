@@ -0,0 +1,109 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/session"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// menuCheckTimeout bounds the permission checks made before a menu is shown
+const menuCheckTimeout = 10 * time.Second
+
+// Permission is a verb on a resource, optionally limited to a namespace and name
+type Permission struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+// String describes the permission the way RBAC rules are written, e.g.
+// `create llminferenceservices.serving.kserve.io in namespace "llm"`
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Name != "" {
+		resource += fmt.Sprintf(" %q", p.Name)
+	}
+
+	if p.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %q", p.Verb, resource, p.Namespace)
+	}
+	return fmt.Sprintf("%s %s", p.Verb, resource)
+}
+
+// DeniedError reports a permission the current identity lacks
+type DeniedError struct {
+	User       string
+	Permission Permission
+	Reason     string
+}
+
+func (e *DeniedError) Error() string {
+	msg := fmt.Sprintf("permission denied: %s cannot %s", e.User, e.Permission)
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg
+}
+
+// Check asks the API server, with a SelfSubjectAccessReview, whether the
+// session's identity (the impersonated one, if any) has the permission.
+// It returns a *DeniedError naming the missing permission when it does not.
+func Check(ctx context.Context, sess *session.Session, perm Permission) error {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:      perm.Verb,
+				Group:     perm.Group,
+				Resource:  perm.Resource,
+				Namespace: perm.Namespace,
+				Name:      perm.Name,
+			},
+		},
+	}
+
+	result, err := sess.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to check permission to %s: %w", perm, err)
+	}
+
+	if !result.Status.Allowed {
+		return &DeniedError{User: identity(sess), Permission: perm, Reason: result.Status.Reason}
+	}
+	return nil
+}
+
+// Denied checks the permission needed by each menu action and returns the
+// actions that are not allowed, mapped to the permission they lack.
+// Actions whose check fails for another reason, including a cancelled ctx,
+// are left enabled; the handler reports the API error when it runs.
+func Denied(ctx context.Context, sess *session.Session, actions map[string]Permission) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, menuCheckTimeout)
+	defer cancel()
+
+	denied := make(map[string]string)
+	for action, perm := range actions {
+		if err := Check(ctx, sess, perm); err != nil {
+			if _, ok := err.(*DeniedError); ok {
+				denied[action] = perm.String()
+			}
+		}
+	}
+	return denied
+}
+
+// identity returns the user the API server evaluates permissions for
+func identity(sess *session.Session) string {
+	if user := sess.Config.Impersonate.UserName; user != "" {
+		return user
+	}
+	return sess.User
+}
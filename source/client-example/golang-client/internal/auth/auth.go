@@ -3,41 +3,177 @@ package auth
 import (
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 )
 
+// Method selects how the client authenticates to the cluster
+type Method string
+
+const (
+	// MethodAuto picks a method from the environment (see Config.Resolve)
+	MethodAuto Method = "auto"
+	// MethodKubeconfig uses the current context of the kubeconfig file
+	MethodKubeconfig Method = "kubeconfig"
+	// MethodPassword logs in to the OpenShift OAuth server with username/password
+	MethodPassword Method = "password"
+	// MethodToken uses a bearer token or token file (e.g. a service account token)
+	MethodToken Method = "token"
+	// MethodInCluster uses the pod's service account when running inside the cluster
+	MethodInCluster Method = "in-cluster"
+	// MethodOIDC logs in through an OpenID Connect provider such as Keycloak
+	MethodOIDC Method = "oidc"
+)
+
+// OIDC login flows
+const (
+	// OIDCFlowBrowser is the authorization code flow with PKCE via a loopback redirect
+	OIDCFlowBrowser = "browser"
+	// OIDCFlowDevice is the device authorization flow for machines without a browser
+	OIDCFlowDevice = "device"
+)
+
+// serviceAccountTokenFile is where Kubernetes mounts the pod's service account token
+const serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
 // Config holds authentication configuration
 type Config struct {
+	// Method is the authentication method in use; never MethodAuto once resolved
+	Method   Method
 	Username string
 	Password string
 	Server   string
+
+	// KubeContext selects a kubeconfig context instead of the current one
+	KubeContext string
+
+	// PromptPassword is called when a password login is needed but Password is empty
+	PromptPassword func() (string, error)
+
+	// Token is a bearer token, used when Method is MethodToken
+	Token string
+	// TokenFile is a file holding a bearer token, re-read when it is rotated
+	TokenFile string
+
+	// CAFile is a PEM bundle used to verify the API and OAuth servers
+	CAFile string
+	// InsecureSkipTLSVerify disables certificate verification (explicit opt-in only)
+	InsecureSkipTLSVerify bool
+
+	// ImpersonateUser and ImpersonateGroups make every request act as another identity
+	ImpersonateUser   string
+	ImpersonateGroups []string
+
+	// OIDC settings, used when Method is MethodOIDC
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCScopes       []string
+	OIDCFlow         string
+	OIDCCallbackPort int
+	// OIDCCAFile is a PEM bundle used to verify the issuer, which is usually
+	// signed by a different CA from the API server; system roots when empty
+	OIDCCAFile string
 }
 
-// LoadFromEnv loads authentication configuration from environment variables
-func LoadFromEnv() (*Config, error) {
-	username := os.Getenv("USER")
-	if username == "" {
-		return nil, fmt.Errorf("USER environment variable is required")
+// Resolve picks the authentication method if it is unset or MethodAuto, then
+// checks that the settings the method needs are present.
+// Automatic selection tries, in order: a token or token file, the in-cluster
+// service account, an existing kubeconfig, and finally username/password.
+func (c *Config) Resolve() error {
+	if c.Method == "" || c.Method == MethodAuto {
+		c.Method = c.detectMethod()
 	}
 
-	password := os.Getenv("PASSWORD")
-	if password == "" {
-		return nil, fmt.Errorf("PASSWORD environment variable is required")
+	if len(c.ImpersonateGroups) > 0 && c.ImpersonateUser == "" {
+		return fmt.Errorf("impersonating groups requires an impersonated user")
 	}
 
-	server := os.Getenv("SERVER")
-	if server == "" {
-		return nil, fmt.Errorf("SERVER environment variable is required")
+	switch c.Method {
+	case MethodKubeconfig:
+		if _, err := os.Stat(KubeconfigPath()); err != nil {
+			return fmt.Errorf("kubeconfig file not found: %s", KubeconfigPath())
+		}
+
+	case MethodInCluster:
+		if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+			return fmt.Errorf("the in-cluster method requires running inside a Kubernetes pod")
+		}
+
+	case MethodPassword:
+		if c.Server == "" {
+			return fmt.Errorf("server URL is required for the password method")
+		}
+		if c.Username == "" {
+			return fmt.Errorf("username is required for the password method")
+		}
+		if c.Password == "" && c.PromptPassword == nil {
+			return fmt.Errorf("password is required for the password method")
+		}
+
+	case MethodToken:
+		if c.Server == "" {
+			return fmt.Errorf("server URL is required for the token method")
+		}
+		if c.Token == "" && c.TokenFile == "" {
+			return fmt.Errorf("a token or token file is required for the token method")
+		}
+		if c.Token != "" && c.TokenFile != "" {
+			return fmt.Errorf("token and token file are mutually exclusive")
+		}
+
+	case MethodOIDC:
+		if c.Server == "" {
+			return fmt.Errorf("server URL is required for the oidc method")
+		}
+		if c.OIDCIssuerURL == "" {
+			return fmt.Errorf("OIDC issuer URL is required for the oidc method")
+		}
+		if c.OIDCClientID == "" {
+			return fmt.Errorf("OIDC client ID is required for the oidc method")
+		}
+		switch c.OIDCFlow {
+		case "":
+			c.OIDCFlow = OIDCFlowBrowser
+		case OIDCFlowBrowser, OIDCFlowDevice:
+		default:
+			return fmt.Errorf("unknown OIDC flow %q (expected %q or %q)", c.OIDCFlow, OIDCFlowBrowser, OIDCFlowDevice)
+		}
+
+	default:
+		return fmt.Errorf("unknown authentication method %q (expected one of: auto, kubeconfig, password, token, in-cluster, oidc)", c.Method)
 	}
 
-	// Ensure server URL doesn't have trailing slash
-	server = strings.TrimSuffix(server, "/")
+	return nil
+}
 
-	return &Config{
-		Username: username,
-		Password: password,
-		Server:   server,
-	}, nil
+// detectMethod picks an authentication method from what the environment provides
+func (c *Config) detectMethod() Method {
+	if c.Token != "" || c.TokenFile != "" {
+		return MethodToken
+	}
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if _, err := os.Stat(serviceAccountTokenFile); err == nil {
+			return MethodInCluster
+		}
+	}
+
+	if _, err := os.Stat(KubeconfigPath()); err == nil {
+		return MethodKubeconfig
+	}
+
+	return MethodPassword
 }
 
+// KubeconfigPath returns the kubeconfig file from KUBECONFIG or ~/.kube/config
+func KubeconfigPath() string {
+	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+		return kubeconfig
+	}
 
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
@@ -0,0 +1,404 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/config"
+	"github.com/bryon/ocp-lister/internal/operation"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/protect"
+	"github.com/bryon/ocp-lister/internal/session"
+	"github.com/bryon/ocp-lister/internal/tui"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes returned by subcommands, so scripts can tell failures apart
+const (
+	ExitOK        = 0
+	ExitError     = 1   // the operation failed
+	ExitUsage     = 2   // invalid command, arguments or configuration
+	ExitDenied    = 3   // the identity lacks a permission (HTTP 403)
+	ExitNotFound  = 4   // the object does not exist (HTTP 404)
+	ExitTimeout   = 124 // the operation hit --timeout
+	ExitCancelled = 130 // interrupted with Ctrl-C
+)
+
+// Invocation holds the parsed arguments of one subcommand
+type Invocation struct {
+	Name      string
+	Namespace string
+	Output    output.Format
+	// Options holds the values of the command's extra options by name, in the
+	// order given
+	Options map[string][]string
+}
+
+// Option returns the last value of an option, or ""
+func (inv Invocation) Option(name string) string {
+	values := inv.Options[name]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// Bool reports whether a boolean option is true. parse has already rejected
+// values strconv.ParseBool does not accept.
+func (inv Invocation) Bool(name string) bool {
+	value, _ := strconv.ParseBool(inv.Option(name))
+	return value
+}
+
+// option is an extra flag of one command, e.g. --display-name
+type option struct {
+	name  string
+	usage string
+	// boolean options take no value
+	boolean bool
+	// repeated options may be given more than once, e.g. --label a=1 --label b=2
+	repeated bool
+}
+
+// command is one subcommand, e.g. "projects delete", mirroring a menu handler
+type command struct {
+	resource string
+	verb     string
+	summary  string
+
+	// name requires a NAME argument (positional or --name)
+	name bool
+	// namespaced accepts -n/--namespace, defaulting to the model namespace
+	namespaced bool
+	// confirm marks destructive commands, which require --yes
+	confirm bool
+	// output is the default -o format of list and get commands; "" for commands without -o
+	output string
+	// options are the command's extra flags
+	options []option
+	// timeout, if set and not 0, replaces --timeout as the operation's time
+	// limit, e.g. while a delete waits
+	timeout func(inv Invocation) (time.Duration, error)
+	// describe, if set, shows what a confirm command will destroy. It runs
+	// before run, and in the REPL before asking for confirmation.
+	describe func(ctx context.Context, sess *session.Session, inv Invocation) error
+
+	run func(ctx context.Context, sess *session.Session, inv Invocation) error
+}
+
+// aliases maps accepted resource names to the canonical one
+var aliases = map[string]string{
+	"project":            "projects",
+	"user":               "users",
+	"model":              "models",
+	"clusterrolebinding": "clusterrolebindings",
+	"crb":                "clusterrolebindings",
+	"crbs":               "clusterrolebindings",
+	"group":              "groups",
+	"tier":               "tiers",
+}
+
+// Run connects, runs the subcommand in args and returns the process exit code.
+// Errors, progress and other status messages go to stderr (Session.Status), so
+// stdout only carries the printed objects.
+func Run(cfg *config.Config, args []string) int {
+	if args[0] == "help" {
+		printCommands(os.Stdout)
+		return ExitOK
+	}
+	if args[0] == "repl" {
+		if len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "Error: repl: unexpected arguments: %s\n", strings.Join(args[1:], " "))
+			return ExitUsage
+		}
+		return runREPL(cfg)
+	}
+	if args[0] == "tui" {
+		if len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "Error: tui: unexpected arguments: %s\n", strings.Join(args[1:], " "))
+			return ExitUsage
+		}
+		return runTUI(cfg)
+	}
+
+	cmd, rest, err := lookup(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		printCommands(os.Stderr)
+		return ExitUsage
+	}
+
+	inv, err := cmd.parse(rest, cfg.Settings)
+	if err == flag.ErrHelp {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitUsage
+	}
+
+	sess, err := session.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return ExitError
+	}
+	sess = sess.WithStatus(os.Stderr)
+
+	// The first Ctrl-C cancels the operation, which then exits with ExitCancelled
+	operation.HandleInterrupts(func() { os.Exit(ExitCancelled) })
+
+	if err := cmd.start(sess, inv, false); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitCode(err)
+	}
+
+	return ExitOK
+}
+
+// runTUI connects and shows the full-screen view until the user quits
+func runTUI(cfg *config.Config) int {
+	sess, err := session.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return ExitError
+	}
+
+	if err := tui.Run(sess); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	logoutOnExit(cfg, sess)
+
+	return ExitOK
+}
+
+// logoutOnExit revokes the session's token when --revoke-on-exit is in effect
+func logoutOnExit(cfg *config.Config, sess *session.Session) {
+	if cfg.Settings.RevokeOnExit {
+		revoked, err := sess.Logout()
+		switch {
+		case err != nil:
+			fmt.Fprintf(sess.Status, "⚠️  Failed to revoke token for %s: %v\n", sess.Cluster, err)
+		case revoked:
+			fmt.Fprintf(sess.Status, "✓ Logged out of %s (token revoked)\n", sess.Cluster)
+		}
+	}
+}
+
+// lookup finds the command named by the leading arguments and returns the rest
+func lookup(args []string) (*command, []string, error) {
+	resource := args[0]
+	if canonical, ok := aliases[resource]; ok {
+		resource = canonical
+	}
+
+	for i := range commands {
+		if commands[i].resource == resource && commands[i].verb == "" {
+			return &commands[i], args[1:], nil
+		}
+	}
+
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+		for i := range commands {
+			if commands[i].resource == resource {
+				return nil, nil, fmt.Errorf("%s: missing action", resource)
+			}
+		}
+		return nil, nil, fmt.Errorf("unknown command %q", args[0])
+	}
+
+	for i := range commands {
+		if commands[i].resource == resource && commands[i].verb == args[1] {
+			return &commands[i], args[2:], nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("unknown command %q", strings.Join(args[:2], " "))
+}
+
+// parse reads the command's flags and positional arguments
+func (c *command) parse(args []string, settings config.Settings) (Invocation, error) {
+	inv := Invocation{Options: make(map[string][]string)}
+	var yes bool
+	var format string
+
+	fs := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	if c.name {
+		fs.StringVar(&inv.Name, "name", "", "object name (or pass it as the first argument)")
+	}
+	if c.namespaced {
+		fs.StringVar(&inv.Namespace, "namespace", settings.ModelNamespace, "namespace")
+		fs.StringVar(&inv.Namespace, "n", settings.ModelNamespace, "namespace (shorthand)")
+	}
+	if c.confirm {
+		fs.BoolVar(&yes, "yes", false, "confirm the deletion (required)")
+	}
+	if c.output != "" {
+		usage := "output format: table, wide, json, yaml, name or jsonpath=TEMPLATE"
+		fs.StringVar(&format, "output", c.output, usage)
+		fs.StringVar(&format, "o", c.output, usage+" (shorthand)")
+	}
+	for _, opt := range c.options {
+		set := func(value string) error {
+			if opt.boolean {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					return errors.New("use true or false")
+				}
+				value = strconv.FormatBool(parsed)
+			}
+			if opt.repeated {
+				inv.Options[opt.name] = append(inv.Options[opt.name], value)
+			} else {
+				inv.Options[opt.name] = []string{value}
+			}
+			return nil
+		}
+		if opt.boolean {
+			fs.BoolFunc(opt.name, opt.usage, set)
+		} else {
+			fs.Func(opt.name, opt.usage, set)
+		}
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ocp-lister [global flags] %s%s\n\n%s\n", c.path(), c.argsUsage(), c.summary)
+		if c.name || c.namespaced || c.confirm || c.output != "" || len(c.options) > 0 {
+			fmt.Fprintln(os.Stderr, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+
+	// Allow flags before and after the NAME argument
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return inv, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if c.name && inv.Name == "" && len(positional) > 0 {
+		inv.Name, positional = positional[0], positional[1:]
+	}
+	if len(positional) > 0 {
+		return inv, fmt.Errorf("%s: unexpected arguments: %s", c.path(), strings.Join(positional, " "))
+	}
+	if c.name && inv.Name == "" {
+		return inv, fmt.Errorf("%s: a name is required", c.path())
+	}
+	if c.namespaced && inv.Namespace == "" {
+		return inv, fmt.Errorf("%s: a namespace is required", c.path())
+	}
+	if c.confirm && !yes {
+		return inv, fmt.Errorf("%s: refusing to delete %s without --yes", c.path(), inv.Name)
+	}
+	if c.output != "" {
+		parsed, err := output.Parse(format)
+		if err != nil {
+			return inv, fmt.Errorf("%s: %w", c.path(), err)
+		}
+		inv.Output = parsed
+	}
+
+	return inv, nil
+}
+
+// start runs the command as one operation, cancelled by Ctrl-C or its time
+// limit. described skips the describe step when the caller has already shown it.
+func (c *command) start(sess *session.Session, inv Invocation, described bool) error {
+	run := func(ctx context.Context) error {
+		if c.describe != nil && !described {
+			if err := c.describe(ctx, sess, inv); err != nil {
+				return err
+			}
+		}
+		return c.run(ctx, sess, inv)
+	}
+	if c.timeout != nil {
+		timeout, err := c.timeout(inv)
+		if err != nil {
+			return err
+		}
+		if timeout > 0 {
+			return sess.RunFor(timeout, run)
+		}
+	}
+	return sess.Run(run)
+}
+
+// path returns the command as typed, e.g. "models deploy"
+func (c *command) path() string {
+	if c.verb == "" {
+		return c.resource
+	}
+	return c.resource + " " + c.verb
+}
+
+// argsUsage describes the command's arguments for the usage line
+func (c *command) argsUsage() string {
+	usage := ""
+	if c.name {
+		usage += " NAME"
+	}
+	if c.namespaced {
+		usage += " [-n NAMESPACE]"
+	}
+	if c.confirm {
+		usage += " --yes"
+	}
+	if c.output != "" {
+		usage += " [-o FORMAT]"
+	}
+	if len(c.options) > 0 {
+		usage += " [flags]"
+	}
+	return usage
+}
+
+// printCommands lists every subcommand
+func printCommands(out io.Writer) {
+	fmt.Fprintln(out, "Usage: ocp-lister [global flags] [command]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Without a command the interactive menu starts. Commands:")
+	fmt.Fprintln(out)
+	for i := range commands {
+		fmt.Fprintf(out, "  %-50s %s\n", commands[i].path()+commands[i].argsUsage(), commands[i].summary)
+	}
+	fmt.Fprintf(out, "  %-50s %s\n", "repl", "Type commands interactively, with history and tab completion")
+	fmt.Fprintf(out, "  %-50s %s\n", "tui", "Browse and manage objects in a full-screen view")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Global flags (see ocp-lister --help) go before the command.")
+	fmt.Fprintf(out, "Exit codes: %d ok, %d error, %d usage, %d permission denied, %d not found, %d timeout, %d cancelled\n",
+		ExitOK, ExitError, ExitUsage, ExitDenied, ExitNotFound, ExitTimeout, ExitCancelled)
+}
+
+// exitCode maps an operation error to an exit code
+func exitCode(err error) int {
+	var denied *access.DeniedError
+	var protected *protect.Error
+	switch {
+	case errors.Is(err, operation.ErrInterrupted):
+		return ExitCancelled
+	case errors.Is(err, operation.ErrTimeout):
+		return ExitTimeout
+	case errors.As(err, &denied), errors.As(err, &protected), apierrors.IsForbidden(err):
+		return ExitDenied
+	case apierrors.IsNotFound(err):
+		return ExitNotFound
+	default:
+		return ExitError
+	}
+}
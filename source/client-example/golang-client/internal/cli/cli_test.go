@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/config"
+	"github.com/bryon/ocp-lister/internal/operation"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/protect"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantPath string
+		wantRest []string
+		wantErr  string
+	}{
+		{args: []string{"projects", "list"}, wantPath: "projects list", wantRest: []string{}},
+		{args: []string{"project", "get", "acme"}, wantPath: "projects get", wantRest: []string{"acme"}},
+		{args: []string{"crb", "list", "-o", "name"}, wantPath: "clusterrolebindings list", wantRest: []string{"-o", "name"}},
+		{args: []string{"whoami"}, wantPath: "whoami", wantRest: []string{}},
+		{args: []string{"projects"}, wantErr: "projects: missing action"},
+		{args: []string{"projects", "-o", "json"}, wantErr: "projects: missing action"},
+		{args: []string{"projects", "explode"}, wantErr: `unknown command "projects explode"`},
+		{args: []string{"pods", "list"}, wantErr: `unknown command "pods list"`},
+		{args: []string{"pods"}, wantErr: `unknown command "pods"`},
+		{args: []string{"users", "update", "alice"}, wantErr: `unknown command "users update"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			cmd, rest, err := lookup(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("lookup() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lookup() error = %v", err)
+			}
+			if cmd.path() != tt.wantPath {
+				t.Errorf("lookup() = %q, want %q", cmd.path(), tt.wantPath)
+			}
+			if !reflect.DeepEqual(rest, tt.wantRest) {
+				t.Errorf("lookup() rest = %q, want %q", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	settings := config.Settings{ModelNamespace: "llm"}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    Invocation
+		wantErr string
+	}{
+		{
+			name: "list default output",
+			args: []string{"projects", "list"},
+			want: Invocation{Output: output.Format{Kind: output.Table}},
+		},
+		{
+			name: "jsonpath output",
+			args: []string{"projects", "list", "-o", "jsonpath={.items[*].metadata.name}"},
+			want: Invocation{Output: output.Format{Kind: output.JSONPath, Template: "{.items[*].metadata.name}"}},
+		},
+		{
+			name:    "bad output",
+			args:    []string{"projects", "list", "-o", "xml"},
+			wantErr: "projects list: ",
+		},
+		{
+			name: "positional name",
+			args: []string{"projects", "get", "acme"},
+			want: Invocation{Name: "acme", Output: output.Format{Kind: output.JSON}},
+		},
+		{
+			name: "name flag",
+			args: []string{"projects", "get", "--name", "acme", "-o", "yaml"},
+			want: Invocation{Name: "acme", Output: output.Format{Kind: output.YAML}},
+		},
+		{
+			name: "flags after the name",
+			args: []string{"projects", "get", "acme", "-o", "name"},
+			want: Invocation{Name: "acme", Output: output.Format{Kind: output.Name}},
+		},
+		{
+			name:    "missing name",
+			args:    []string{"projects", "get"},
+			wantErr: "projects get: a name is required",
+		},
+		{
+			name:    "extra argument",
+			args:    []string{"projects", "get", "acme", "other"},
+			wantErr: "projects get: unexpected arguments: other",
+		},
+		{
+			name: "default namespace",
+			args: []string{"models", "list"},
+			want: Invocation{Namespace: "llm", Output: output.Format{Kind: output.Table}},
+		},
+		{
+			name: "namespace shorthand",
+			args: []string{"models", "get", "granite", "-n", "acme-inc-models"},
+			want: Invocation{Name: "granite", Namespace: "acme-inc-models", Output: output.Format{Kind: output.JSON}},
+		},
+		{
+			name:    "empty namespace",
+			args:    []string{"models", "list", "--namespace="},
+			wantErr: "models list: a namespace is required",
+		},
+		{
+			name:    "delete without yes",
+			args:    []string{"projects", "delete", "acme"},
+			wantErr: "projects delete: refusing to delete acme without --yes",
+		},
+		{
+			name: "delete with yes",
+			args: []string{"projects", "delete", "acme", "--yes"},
+			want: Invocation{Name: "acme"},
+		},
+		{
+			name: "boolean option",
+			args: []string{"projects", "delete", "acme", "--yes", "--wait"},
+			want: Invocation{Name: "acme", Options: map[string][]string{"wait": {"true"}}},
+		},
+		{
+			name: "boolean option value",
+			args: []string{"projects", "delete", "acme", "--yes", "--wait=0"},
+			want: Invocation{Name: "acme", Options: map[string][]string{"wait": {"false"}}},
+		},
+		{
+			name:    "bad boolean option value",
+			args:    []string{"projects", "delete", "acme", "--yes", "--wait=bogus"},
+			wantErr: "use true or false",
+		},
+		{
+			name: "repeated option",
+			args: []string{"projects", "update", "acme", "--label", "a=1", "--label", "b-", "--display-name", "Acme"},
+			want: Invocation{Name: "acme", Options: map[string][]string{"label": {"a=1", "b-"}, "display-name": {"Acme"}}},
+		},
+		{
+			name: "single option keeps the last value",
+			args: []string{"projects", "update", "acme", "--description", "one", "--description", "two"},
+			want: Invocation{Name: "acme", Options: map[string][]string{"description": {"two"}}},
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"projects", "list", "--bogus"},
+			wantErr: "flag provided but not defined: -bogus",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, rest, err := lookup(tt.args)
+			if err != nil {
+				t.Fatalf("lookup() error = %v", err)
+			}
+
+			got, err := cmd.parse(rest, settings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+
+			if tt.want.Options == nil {
+				tt.want.Options = map[string][]string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvocationBool(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   bool
+	}{
+		{values: nil, want: false},
+		{values: []string{"true"}, want: true},
+		{values: []string{"false"}, want: false},
+		{values: []string{"true", "false"}, want: false},
+	}
+
+	for _, tt := range tests {
+		inv := Invocation{Options: map[string][]string{"wait": tt.values}}
+		if got := inv.Bool("wait"); got != tt.want {
+			t.Errorf("Bool() with %q = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"pods", "list"},
+		{"projects"},
+		{"projects", "get"},
+		{"projects", "delete", "acme"},
+		{"projects", "delete", "acme", "--yes", "--wait=bogus"},
+		{"projects", "list", "-o", "xml"},
+	}
+
+	cfg := &config.Config{Settings: config.Settings{ModelNamespace: "llm"}}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if got := Run(cfg, args); got != ExitUsage {
+				t.Errorf("Run() = %d, want %d", got, ExitUsage)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	namespaces := schema.GroupResource{Resource: "namespaces"}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "interrupted", err: fmt.Errorf("delete: %w", operation.ErrInterrupted), want: ExitCancelled},
+		{name: "timeout", err: fmt.Errorf("delete: %w", operation.ErrTimeout), want: ExitTimeout},
+		{name: "denied", err: &access.DeniedError{User: "alice", Permission: access.Permission{Verb: "delete", Resource: "namespaces"}}, want: ExitDenied},
+		{name: "wrapped denied", err: fmt.Errorf("only cluster admins: %w", &access.DeniedError{User: "alice"}), want: ExitDenied},
+		{name: "protected", err: &protect.Error{Kind: "project", Name: "kube-system", Reason: "system"}, want: ExitDenied},
+		{name: "forbidden", err: fmt.Errorf("error getting project: %w", apierrors.NewForbidden(namespaces, "acme", errors.New("no"))), want: ExitDenied},
+		{name: "not found", err: fmt.Errorf("error getting project: %w", apierrors.NewNotFound(namespaces, "acme")), want: ExitNotFound},
+		{name: "other API error", err: apierrors.NewConflict(namespaces, "acme", errors.New("changed")), want: ExitError},
+		{name: "plain error", err: errors.New("boom"), want: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/objects/clusterrolebindings"
+	"github.com/bryon/ocp-lister/internal/objects/groups"
+	"github.com/bryon/ocp-lister/internal/objects/models"
+	"github.com/bryon/ocp-lister/internal/objects/projects"
+	"github.com/bryon/ocp-lister/internal/objects/tiers"
+	"github.com/bryon/ocp-lister/internal/objects/users"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
+	"github.com/bryon/ocp-lister/internal/whoami"
+)
+
+// commands lists every subcommand in the order shown by "ocp-lister help"
+var commands = []command{
+	{resource: "projects", verb: "list", summary: "List projects", output: output.Table,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleList(ctx, sess, inv.Output)
+		}},
+	{resource: "projects", verb: "get", summary: "Show a project", name: true, output: output.JSON,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleGet(ctx, sess, inv.Name, inv.Output)
+		}},
+	{resource: "projects", verb: "create", summary: "Create a project", name: true,
+		options: []option{
+			{name: "display-name", usage: "human-readable project name"},
+			{name: "description", usage: "project description"},
+			{name: "as-namespace", boolean: true, usage: "create a plain namespace, skipping the project template (cluster admins only)"},
+		},
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleCreate(ctx, sess, inv.Name, projects.CreateOptions{
+				DisplayName: inv.Option("display-name"),
+				Description: inv.Option("description"),
+				AsNamespace: inv.Bool("as-namespace"),
+			})
+		}},
+	{resource: "projects", verb: "provision", summary: "Create a tenant project with RoleBindings, quota, limits and network policy", name: true,
+		options: []option{
+			{name: "display-name", usage: "human-readable project name"},
+			{name: "description", usage: "project description"},
+			{name: "admin-group", usage: "group bound to the admin role"},
+			{name: "edit-group", usage: "group bound to the edit role"},
+			{name: "cpu", usage: "CPU request quota (default " + projects.DefaultTenantCPU + ")"},
+			{name: "memory", usage: "memory request quota (default " + projects.DefaultTenantMemory + ")"},
+			{name: "gpus", usage: "requests.nvidia.com/gpu quota (default " + projects.DefaultTenantGPUs + ")"},
+			{name: "as-namespace", boolean: true, usage: "create a plain namespace, skipping the project template (cluster admins only)"},
+		},
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleProvisionTenant(ctx, sess, inv.Name, projects.TenantOptions{
+				CreateOptions: projects.CreateOptions{
+					DisplayName: inv.Option("display-name"),
+					Description: inv.Option("description"),
+					AsNamespace: inv.Bool("as-namespace"),
+				},
+				AdminGroup: inv.Option("admin-group"),
+				EditGroup:  inv.Option("edit-group"),
+				CPU:        inv.Option("cpu"),
+				Memory:     inv.Option("memory"),
+				GPUs:       inv.Option("gpus"),
+			})
+		}},
+	{resource: "projects", verb: "update", summary: "Change a project's labels, annotations, display name or description", name: true,
+		options: []option{
+			{name: "display-name", usage: "new display name"},
+			{name: "description", usage: "new description"},
+			{name: "label", repeated: true, usage: "key=value to set a label or key- to remove it (repeatable)"},
+			{name: "annotation", repeated: true, usage: "key=value to set an annotation or key- to remove it (repeatable)"},
+			{name: "dry-run", boolean: true, usage: "show the changes without applying them"},
+		},
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleUpdate(ctx, sess, inv.Name, projects.UpdateOptions{
+				DisplayName: inv.Option("display-name"),
+				Description: inv.Option("description"),
+				Labels:      inv.Options["label"],
+				Annotations: inv.Options["annotation"],
+				DryRun:      inv.Bool("dry-run"),
+			})
+		}},
+	{resource: "projects", verb: "delete", summary: "Delete a project", name: true, confirm: true,
+		options: []option{
+			{name: "wait", boolean: true, usage: "wait for the project to be gone, showing what remains"},
+			{name: "wait-timeout", usage: "how long --wait waits before reporting what blocks the deletion (default " + projects.DefaultDeleteWaitTimeout.String() + ")"},
+		},
+		timeout: func(inv Invocation) (time.Duration, error) {
+			opts, err := deleteOptions(inv)
+			return opts.OperationTimeout(), err
+		},
+		describe: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.DescribeForDeletion(ctx, sess, inv.Name)
+		},
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			opts, err := deleteOptions(inv)
+			if err != nil {
+				return err
+			}
+			return projects.Delete(ctx, sess, inv.Name, opts)
+		}},
+	{resource: "projects", verb: "annotate", summary: "Add the test annotation to a project", name: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return projects.HandleAddAnnotation(ctx, sess, inv.Name)
+		}},
+
+	{resource: "users", verb: "list", summary: "List users", output: output.Table,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return users.HandleList(ctx, sess, inv.Output)
+		}},
+	{resource: "users", verb: "get", summary: "Show a user", name: true, output: output.JSON,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return users.HandleGet(ctx, sess, inv.Name, inv.Output)
+		}},
+	{resource: "users", verb: "create", summary: "Create a user", name: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return users.HandleCreate(ctx, sess, inv.Name)
+		}},
+	{resource: "users", verb: "delete", summary: "Delete a user", name: true, confirm: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return users.HandleDelete(ctx, sess, inv.Name)
+		}},
+	{resource: "users", verb: "annotate", summary: "Add the test annotation to a user", name: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return users.HandleAddAnnotation(ctx, sess, inv.Name)
+		}},
+
+	{resource: "groups", verb: "list", summary: "List groups and the MaaS tiers they map to", output: output.Table,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return groups.HandleList(ctx, sess, inv.Output)
+		}},
+	{resource: "groups", verb: "get", summary: "Show a group", name: true, output: output.JSON,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return groups.HandleGet(ctx, sess, inv.Name, inv.Output)
+		}},
+
+	{resource: "clusterrolebindings", verb: "list", summary: "List cluster role bindings", output: output.Table,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return clusterrolebindings.HandleList(ctx, sess, inv.Output)
+		}},
+	{resource: "clusterrolebindings", verb: "get", summary: "Show a cluster role binding", name: true, output: output.JSON,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return clusterrolebindings.HandleGet(ctx, sess, inv.Name, inv.Output)
+		}},
+	{resource: "clusterrolebindings", verb: "annotate", summary: "Add the test annotation to a cluster role binding", name: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return clusterrolebindings.HandleAddAnnotation(ctx, sess, inv.Name)
+		}},
+
+	{resource: "models", verb: "list", summary: "List models in a namespace", output: output.Table, namespaced: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return models.HandleList(ctx, sess, inv.Namespace, inv.Output)
+		}},
+	{resource: "models", verb: "get", summary: "Show a model", name: true, output: output.JSON, namespaced: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return models.HandleGet(ctx, sess, inv.Name, inv.Namespace, inv.Output)
+		}},
+	{resource: "models", verb: "deploy", summary: "Deploy a model", name: true, namespaced: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return models.HandleDeploy(ctx, sess, inv.Name, inv.Namespace)
+		}},
+	{resource: "models", verb: "undeploy", summary: "Undeploy a model", name: true, namespaced: true, confirm: true,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return models.HandleUndeploy(ctx, sess, inv.Name, inv.Namespace)
+		}},
+
+	{resource: "tiers", verb: "list", summary: "List MaaS tiers and their groups", output: output.Table,
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return tiers.HandleList(ctx, sess, inv.Output)
+		}},
+
+	{resource: "whoami", summary: "Show the identity, groups, MaaS tier and token expiry",
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			return whoami.HandleShow(ctx, sess)
+		}},
+	{resource: "logout", summary: "Revoke the cached login token",
+		run: func(ctx context.Context, sess *session.Session, inv Invocation) error {
+			revoked, err := sess.Logout()
+			if err != nil {
+				return err
+			}
+			if revoked {
+				fmt.Fprintf(os.Stderr, "✓ Logged out of %s (token revoked)\n", sess.Cluster)
+			} else {
+				fmt.Fprintf(os.Stderr, "Credentials for %s were not issued by this tool; nothing to revoke\n", sess.Cluster)
+			}
+			return nil
+		}},
+}
+
+// deleteOptions reads the --wait and --wait-timeout options of projects delete
+func deleteOptions(inv Invocation) (projects.DeleteOptions, error) {
+	opts := projects.DeleteOptions{Wait: inv.Bool("wait")}
+	if value := inv.Option("wait-timeout"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --wait-timeout %q: %w", value, err)
+		}
+		opts.WaitTimeout = timeout
+	}
+	return opts, nil
+}
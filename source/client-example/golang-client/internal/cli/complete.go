@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/config"
+	"github.com/bryon/ocp-lister/internal/objects/clusterrolebindings"
+	"github.com/bryon/ocp-lister/internal/objects/groups"
+	"github.com/bryon/ocp-lister/internal/objects/models"
+	"github.com/bryon/ocp-lister/internal/objects/projects"
+	"github.com/bryon/ocp-lister/internal/objects/users"
+	"github.com/bryon/ocp-lister/internal/session"
+	"golang.org/x/term"
+)
+
+// How long completion waits for the cluster, and how long fetched names are reused
+const (
+	completionTimeout = 5 * time.Second
+	completionTTL     = 30 * time.Second
+)
+
+// nameSources fetch the live names offered by tab completion, by resource
+var nameSources = map[string]func(ctx context.Context, sess *session.Session, namespace string) ([]string, error){
+	"projects": func(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+		return projects.ListProjects(ctx, sess)
+	},
+	"users": func(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+		return users.ListUsers(ctx, sess)
+	},
+	"groups": func(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+		return groups.ListGroups(ctx, sess)
+	},
+	"clusterrolebindings": func(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+		return clusterrolebindings.ListClusterRoleBindings(ctx, sess)
+	},
+	"models": models.ListModels,
+}
+
+// noNameCompletion lists verbs whose NAME is a new object, so existing names are not offered
+var noNameCompletion = map[string]bool{"create": true, "deploy": true}
+
+// cachedNames is one fetched list of names
+type cachedNames struct {
+	names   []string
+	fetched time.Time
+}
+
+// nameCache keeps recently fetched names so every Tab does not hit the cluster
+type nameCache struct {
+	sess    *session.Session
+	entries map[string]cachedNames
+}
+
+// newNameCache creates an empty cache for a session
+func newNameCache(sess *session.Session) *nameCache {
+	return &nameCache{sess: sess, entries: make(map[string]cachedNames)}
+}
+
+// names returns the names of a resource, fetching them when the cached list
+// is missing or stale. Errors, e.g. a denied list, just mean no completions.
+func (c *nameCache) names(resource, namespace string) []string {
+	source, ok := nameSources[resource]
+	if !ok {
+		return nil
+	}
+
+	key := resource + "/" + namespace
+	if entry, ok := c.entries[key]; ok && time.Since(entry.fetched) < completionTTL {
+		return entry.names
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	names, err := source(ctx, c.sess, namespace)
+	if err != nil {
+		return nil
+	}
+
+	sort.Strings(names)
+	c.entries[key] = cachedNames{names: names, fetched: time.Now()}
+	return names
+}
+
+// invalidate drops the cached names of a resource after it was changed
+func (c *nameCache) invalidate(resource string) {
+	for key := range c.entries {
+		if strings.HasPrefix(key, resource+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// completer completes REPL lines on Tab. The first Tab extends the word to the
+// longest common prefix of the candidates; a second Tab lists them.
+type completer struct {
+	settings config.Settings
+	names    *nameCache
+	terminal *term.Terminal
+	// last is the line at the previous key press when it was a Tab
+	last   string
+	tabbed bool
+}
+
+// complete implements term.Terminal's AutoCompleteCallback
+func (c *completer) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		c.tabbed = false
+		return "", 0, false
+	}
+
+	head := line[:pos]
+	words := strings.Fields(head)
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(head, " ") {
+		current, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	var matches []string
+	for _, candidate := range c.candidates(words, current) {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	listed := c.tabbed && c.last == line
+	c.last, c.tabbed = line, true
+
+	completion := current
+	switch {
+	case len(matches) == 1:
+		completion = matches[0]
+		if !strings.HasSuffix(completion, "=") {
+			completion += " "
+		}
+	case len(matches) > 1:
+		completion = commonPrefix(matches)
+		if completion == current && listed {
+			c.terminal.Write([]byte(strings.Join(matches, "  ") + "\n"))
+		}
+	}
+
+	newHead := head[:len(head)-len(current)] + completion
+	return newHead + line[pos:], len(newHead), true
+}
+
+// candidates returns the possible words after the given complete words
+func (c *completer) candidates(words []string, current string) []string {
+	if len(words) > 0 {
+		switch words[len(words)-1] {
+		case "-n", "--namespace", "-namespace":
+			return c.names.names("projects", "")
+		case "-o", "--output", "-output":
+			return []string{"table", "wide", "json", "yaml", "name", "jsonpath="}
+		}
+	}
+
+	switch len(words) {
+	case 0:
+		return firstWords()
+	case 1:
+		if resource, ok := canonicalResource(words[0]); ok {
+			return verbsOf(resource)
+		}
+		if isVerb(words[0]) {
+			return resourcesWith(words[0])
+		}
+		return nil
+	}
+
+	cmd, rest, err := lookup(reorder(words))
+	if err != nil {
+		return nil
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return cmd.flags()
+	}
+	if !cmd.name || noNameCompletion[cmd.verb] || hasName(rest) {
+		return nil
+	}
+
+	namespace := ""
+	if cmd.namespaced {
+		namespace = flagValue(rest, c.settings.ModelNamespace, "n", "namespace")
+	}
+	return c.names.names(cmd.resource, namespace)
+}
+
+// firstWords returns the words a REPL line can start with
+func firstWords() []string {
+	words := []string{"help", "exit", "quit"}
+	seen := make(map[string]bool)
+	for i := range commands {
+		for _, word := range []string{commands[i].resource, commands[i].verb} {
+			if word != "" && !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// verbsOf returns the verbs of a resource
+func verbsOf(resource string) []string {
+	var verbs []string
+	for i := range commands {
+		if commands[i].resource == resource && commands[i].verb != "" {
+			verbs = append(verbs, commands[i].verb)
+		}
+	}
+	return verbs
+}
+
+// resourcesWith returns the resources, and their aliases, that have a verb
+func resourcesWith(verb string) []string {
+	var resources []string
+	for i := range commands {
+		if commands[i].verb != verb {
+			continue
+		}
+		resources = append(resources, commands[i].resource)
+		for alias, canonical := range aliases {
+			if canonical == commands[i].resource {
+				resources = append(resources, alias)
+			}
+		}
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// flags returns the flags a command accepts
+func (c *command) flags() []string {
+	var flags []string
+	if c.name {
+		flags = append(flags, "--name")
+	}
+	if c.namespaced {
+		flags = append(flags, "-n", "--namespace")
+	}
+	if c.confirm {
+		flags = append(flags, "--yes")
+	}
+	if c.output != "" {
+		flags = append(flags, "-o", "--output")
+	}
+	for _, opt := range c.options {
+		flags = append(flags, "--"+opt.name)
+	}
+	return flags
+}
+
+// commonPrefix returns the longest prefix shared by all words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
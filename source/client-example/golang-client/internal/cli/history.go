@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory bounds the number of REPL lines kept on disk
+const maxHistory = 1000
+
+// history records REPL lines and appends each one to a file, so they can be
+// recalled with the arrow keys in later sessions. It implements term.History.
+type history struct {
+	path    string
+	entries []string // oldest first
+}
+
+// historyPath returns the file holding the REPL history
+func historyPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(dir, "ocp-lister", "history"), nil
+}
+
+// loadHistory reads the history file. A missing file is an empty history.
+func loadHistory(path string) (*history, error) {
+	h := &history{path: path}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+
+	return h, scanner.Err()
+}
+
+// Add records a line and appends it to the history file
+func (h *history) Add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+
+	if err := h.append(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// append writes one line to the history file, rewriting it once it grows
+// well past maxHistory
+func (h *history) append(entry string) error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	if info, err := os.Stat(h.path); err == nil && info.Size() > 2*maxHistory*80 {
+		data := strings.Join(h.entries, "\n") + "\n"
+		if err := os.WriteFile(h.path, []byte(data), 0o600); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, entry); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of lines in the history
+func (h *history) Len() int {
+	return len(h.entries)
+}
+
+// At returns a line, 0 being the most recent
+func (h *history) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,415 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/config"
+	"github.com/bryon/ocp-lister/internal/menu"
+	"github.com/bryon/ocp-lister/internal/operation"
+	"github.com/bryon/ocp-lister/internal/session"
+	"golang.org/x/term"
+)
+
+// replPrompt is shown before every REPL line
+const replPrompt = "ocp-lister> "
+
+// changingVerbs add or remove objects, so the names cached for completion are dropped after them
+var changingVerbs = map[string]bool{"create": true, "provision": true, "delete": true, "deploy": true, "undeploy": true}
+
+// runREPL connects and runs the REPL until exit or the end of the input
+func runREPL(cfg *config.Config) int {
+	sess, err := session.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return ExitError
+	}
+
+	// The first Ctrl-C cancels the running command; pressed again it exits
+	operation.HandleInterrupts(func() { os.Exit(ExitCancelled) })
+
+	REPL(cfg.Settings, sess)
+	logoutOnExit(cfg, sess)
+
+	return ExitOK
+}
+
+// REPL reads commands such as "get model NAME -n NAMESPACE" until exit or the
+// end of the input. On a terminal it keeps a persistent history and completes
+// commands, flags and live object names with Tab.
+func REPL(settings config.Settings, sess *session.Session) {
+	r := &repl{settings: settings, sess: sess, names: newNameCache(sess)}
+	readLine := r.lineReader()
+
+	fmt.Println("Type a command such as 'list projects' or 'get model NAME -n NAMESPACE', 'help' or 'exit'.")
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if r.execute(line) {
+			return
+		}
+	}
+}
+
+// repl holds the state of one REPL
+type repl struct {
+	settings config.Settings
+	sess     *session.Session
+	names    *nameCache
+}
+
+// lineReader returns the function reading REPL lines: a terminal with history
+// and completion when stdin is one, otherwise the shared menu input
+func (r *repl) lineReader() func() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return func() (string, error) { return menu.ReadLine(replPrompt) }
+	}
+
+	e := &editor{
+		input:     &interruptReader{Reader: os.Stdin},
+		output:    os.Stdout,
+		completer: &completer{settings: r.settings, names: r.names},
+	}
+	if path, err := historyPath(); err == nil {
+		h, err := loadHistory(path)
+		if err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+		e.history = h
+	}
+	e.reset()
+
+	// The terminal is only raw while a line is edited, so commands print normally
+	return func() (string, error) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", fmt.Errorf("failed to set up terminal: %w", err)
+		}
+		defer term.Restore(fd, state)
+
+		if width, height, err := term.GetSize(fd); err == nil {
+			e.terminal.SetSize(width, height)
+		}
+		return e.readLine()
+	}
+}
+
+// editor edits REPL lines on a terminal. x/term reports Ctrl-C as io.EOF, like
+// Ctrl-D, and keeps the abandoned line, so after a Ctrl-C the editor starts a
+// fresh terminal with the same history and completion instead of exiting.
+type editor struct {
+	input     *interruptReader
+	output    io.Writer
+	history   term.History
+	completer *completer
+	terminal  *term.Terminal
+}
+
+// reset replaces the terminal, dropping the line being edited
+func (e *editor) reset() {
+	e.terminal = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{e.input, e.output}, replPrompt)
+	if e.history != nil {
+		e.terminal.History = e.history
+	} else {
+		e.history = e.terminal.History
+	}
+	e.completer.terminal = e.terminal
+	e.terminal.AutoCompleteCallback = e.completer.complete
+}
+
+// readLine reads the next line. Ctrl-C clears the line and prompts again;
+// only Ctrl-D on an empty line returns io.EOF.
+func (e *editor) readLine() (string, error) {
+	for {
+		e.input.interrupted = false
+		line, err := e.terminal.ReadLine()
+		if err == io.EOF && e.input.interrupted {
+			fmt.Fprint(e.output, "^C\r\n")
+			e.reset()
+			continue
+		}
+		return line, err
+	}
+}
+
+// keyCtrlC is the byte a raw terminal sends for Ctrl-C
+const keyCtrlC = 3
+
+// interruptReader notes the Ctrl-C keys read from a raw terminal. A read stops
+// after a Ctrl-C, so the keys typed after it go to the next terminal.
+type interruptReader struct {
+	io.Reader
+	interrupted bool
+	pending     []byte
+}
+
+func (r *interruptReader) Read(p []byte) (int, error) {
+	var n int
+	var err error
+	if len(r.pending) > 0 {
+		n = copy(p, r.pending)
+		r.pending = r.pending[n:]
+	} else {
+		n, err = r.Reader.Read(p)
+	}
+
+	if i := bytes.IndexByte(p[:n], keyCtrlC); i >= 0 {
+		r.pending = append(append([]byte(nil), p[i+1:n]...), r.pending...)
+		r.interrupted = true
+		n = i + 1
+	}
+	return n, err
+}
+
+// execute runs one REPL line and reports whether the REPL should exit
+func (r *repl) execute(line string) bool {
+	args, err := splitArgs(line)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		printREPLHelp(os.Stdout)
+		return false
+	}
+
+	// "get models" without a name lists them, as kubectl does
+	args = reorder(args)
+	if len(args) >= 2 && args[1] == "get" && !hasName(args[2:]) {
+		args[1] = "list"
+	}
+
+	cmd, rest, err := lookup(args)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+
+	confirmed := hasFlag(rest, "yes")
+	if cmd.confirm && !confirmed {
+		rest = append(rest, "--yes")
+	}
+
+	inv, err := cmd.parse(rest, r.settings)
+	if err == flag.ErrHelp {
+		return false
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+
+	// Show what will be destroyed, then ask for the name instead of requiring --yes
+	described := false
+	if cmd.confirm && !confirmed {
+		if cmd.describe != nil {
+			if err := r.sess.Run(func(ctx context.Context) error {
+				return cmd.describe(ctx, r.sess, inv)
+			}); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return false
+			}
+			described = true
+		}
+		if !menu.ConfirmName(strings.TrimSuffix(cmd.resource, "s"), inv.Name) {
+			fmt.Println("Cancelled.")
+			return false
+		}
+	}
+
+	if err := cmd.start(r.sess, inv, described); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+
+	if changingVerbs[cmd.verb] {
+		r.names.invalidate(cmd.resource)
+	}
+
+	return false
+}
+
+// reorder rewrites verb-first input such as "get model NAME" to the
+// "model get NAME" order of the subcommands
+func reorder(args []string) []string {
+	if len(args) < 2 || !isVerb(args[0]) {
+		return args
+	}
+	if _, ok := canonicalResource(args[0]); ok {
+		return args
+	}
+	return append([]string{args[1], args[0]}, args[2:]...)
+}
+
+// canonicalResource returns the canonical name of a resource or alias
+func canonicalResource(word string) (string, bool) {
+	if canonical, ok := aliases[word]; ok {
+		return canonical, true
+	}
+	for i := range commands {
+		if commands[i].resource == word {
+			return word, true
+		}
+	}
+	return "", false
+}
+
+// isVerb reports whether word is the action of any command
+func isVerb(word string) bool {
+	for i := range commands {
+		if commands[i].verb != "" && commands[i].verb == word {
+			return true
+		}
+	}
+	return false
+}
+
+// valueFlags are the command flags that take a value
+var valueFlags = map[string]bool{"n": true, "namespace": true, "o": true, "output": true, "name": true}
+
+// takesValue reports whether a flag of any command takes a value
+func takesValue(name string) bool {
+	if valueFlags[name] {
+		return true
+	}
+	for i := range commands {
+		for _, opt := range commands[i].options {
+			if opt.name == name && !opt.boolean {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flagName returns the name of a flag argument such as "--namespace=x", or "" for other arguments
+func flagName(arg string) string {
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return ""
+	}
+	name := strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// positionals returns the arguments that are not flags or flag values
+func positionals(args []string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		name := flagName(args[i])
+		switch {
+		case name == "":
+			result = append(result, args[i])
+		case takesValue(name) && !strings.Contains(args[i], "="):
+			i++
+		}
+	}
+	return result
+}
+
+// hasFlag reports whether a flag is among the arguments
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if flagName(arg) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasName reports whether the arguments include a NAME, positional or --name
+func hasName(args []string) bool {
+	return len(positionals(args)) > 0 || hasFlag(args, "name")
+}
+
+// flagValue returns the value of the last of the named flags, or def
+func flagValue(args []string, def string, names ...string) string {
+	value := def
+	for i, arg := range args {
+		name := flagName(arg)
+		for _, n := range names {
+			if name != n {
+				continue
+			}
+			if j := strings.Index(arg, "="); j >= 0 {
+				value = arg[j+1:]
+			} else if i+1 < len(args) {
+				value = args[i+1]
+			}
+		}
+	}
+	return value
+}
+
+// splitArgs splits a line into words like a shell, honouring single and double quotes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	for _, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// printREPLHelp describes the REPL syntax and lists the commands
+func printREPLHelp(out io.Writer) {
+	fmt.Fprintln(out, "Commands are typed as RESOURCE ACTION or ACTION RESOURCE, e.g.")
+	fmt.Fprintln(out, "  list projects")
+	fmt.Fprintln(out, "  get model acme-inc-model-1 -n acme-inc-models -o yaml")
+	fmt.Fprintln(out, "  users delete alice")
+	fmt.Fprintln(out, "Tab completes commands, flags and object names; a second Tab lists the choices.")
+	fmt.Fprintln(out, "Destructive commands ask for confirmation unless --yes is given. Type 'exit' to leave.")
+	fmt.Fprintln(out)
+	for i := range commands {
+		fmt.Fprintf(out, "  %-50s %s\n", commands[i].path()+commands[i].argsUsage(), commands[i].summary)
+	}
+}
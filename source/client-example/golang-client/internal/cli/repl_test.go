@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bryon/ocp-lister/internal/config"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr string
+	}{
+		{line: "", want: nil},
+		{line: "   ", want: nil},
+		{line: "projects list", want: []string{"projects", "list"}},
+		{line: "  projects \t list  ", want: []string{"projects", "list"}},
+		{line: `projects create acme --display-name "Acme Inc"`, want: []string{"projects", "create", "acme", "--display-name", "Acme Inc"}},
+		{line: `projects create acme --description 'It''s fine'`, want: []string{"projects", "create", "acme", "--description", "Its fine"}},
+		{line: `--display-name="Acme Inc"`, want: []string{"--display-name=Acme Inc"}},
+		{line: `say "it's"`, want: []string{"say", "it's"}},
+		{line: `say 'a "b" c'`, want: []string{"say", `a "b" c`}},
+		{line: `empty ""`, want: []string{"empty", ""}},
+		{line: `projects "unterminated`, wantErr: `unterminated " quote`},
+		{line: `projects 'unterminated`, wantErr: "unterminated ' quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := splitArgs(tt.line)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("splitArgs(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitArgs(%q) error = %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorder(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"get", "model", "granite"}, want: []string{"model", "get", "granite"}},
+		{args: []string{"list", "projects", "-o", "name"}, want: []string{"projects", "list", "-o", "name"}},
+		{args: []string{"delete", "project", "acme", "--yes"}, want: []string{"project", "delete", "acme", "--yes"}},
+		{args: []string{"models", "get", "granite"}, want: []string{"models", "get", "granite"}},
+		{args: []string{"projects", "list"}, want: []string{"projects", "list"}},
+		{args: []string{"whoami"}, want: []string{"whoami"}},
+		{args: []string{"list"}, want: []string{"list"}},
+		{args: []string{"bogus", "projects"}, want: []string{"bogus", "projects"}},
+		{args: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := reorder(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reorder(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionals(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"acme"}, want: []string{"acme"}},
+		{args: []string{"-n", "llm", "granite"}, want: []string{"granite"}},
+		{args: []string{"--namespace=llm", "granite"}, want: []string{"granite"}},
+		{args: []string{"acme", "--label", "a=1", "--yes"}, want: []string{"acme"}},
+		{args: []string{"--yes", "acme"}, want: []string{"acme"}},
+		{args: []string{"-o", "json"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := positionals(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("positionals(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunREPLUsage(t *testing.T) {
+	cfg := &config.Config{Settings: config.Settings{ModelNamespace: "llm"}}
+	if got := Run(cfg, []string{"repl", "extra"}); got != ExitUsage {
+		t.Errorf("Run(repl extra) = %d, want %d", got, ExitUsage)
+	}
+}
+
+func TestEditorCtrlC(t *testing.T) {
+	var out bytes.Buffer
+	e := &editor{
+		input:     &interruptReader{Reader: strings.NewReader("list pro\x03whoami\r\x04")},
+		output:    &out,
+		completer: &completer{},
+	}
+	e.reset()
+
+	// Ctrl-C drops "list pro" and the next line is read as typed
+	line, err := e.readLine()
+	if err != nil || line != "whoami" {
+		t.Fatalf("readLine() = %q, %v; want whoami", line, err)
+	}
+	if !strings.Contains(out.String(), "^C") {
+		t.Errorf("output %q does not show the Ctrl-C", out.String())
+	}
+	if entry := e.history.At(0); entry != "whoami" {
+		t.Errorf("last history entry = %q, want whoami", entry)
+	}
+
+	// Ctrl-D on an empty line ends the input
+	if line, err := e.readLine(); err != io.EOF {
+		t.Errorf("readLine() = %q, %v; want io.EOF", line, err)
+	}
+}
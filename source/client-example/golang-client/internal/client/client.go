@@ -1,7 +1,6 @@
 package client
 
 import (
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -9,10 +8,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -27,24 +26,36 @@ type tokenResponse struct {
 
 // getOAuthToken obtains an OAuth token from OpenShift using username/password
 // Uses the challenge-response flow similar to oc login
-func getOAuthToken(server, username, password string) (string, error) {
-	// Create HTTP client that accepts insecure certificates
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{
-		Transport: tr,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Don't follow redirects automatically
-			return http.ErrUseLastResponse
-		},
+func getOAuthToken(server, username, password string, tlsConfig rest.TLSClientConfig) (*Token, error) {
+	// Create HTTP client that verifies the server certificate
+	client, err := newHTTPClient(server, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// Don't follow redirects automatically
+		return http.ErrUseLastResponse
+	}
+
+	// Look up the OAuth server, which lives on its own route on OpenShift
+	metadata, err := discoverOAuthServer(client, server)
+	if err != nil {
+		return nil, err
 	}
 
 	// Step 1: Request authorization with challenge
-	authURL := fmt.Sprintf("%s/oauth/authorize?client_id=openshift-challenging-client&response_type=token", strings.TrimSuffix(server, "/"))
-	req, err := http.NewRequest("GET", authURL, nil)
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
 	if err != nil {
-		return "", fmt.Errorf("failed to create auth request: %w", err)
+		return nil, fmt.Errorf("invalid authorization endpoint %q: %w", metadata.AuthorizationEndpoint, err)
+	}
+	query := authURL.Query()
+	query.Set("client_id", "openshift-challenging-client")
+	query.Set("response_type", "token")
+	authURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", authURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	// Use basic auth
@@ -54,7 +65,7 @@ func getOAuthToken(server, username, password string) (string, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to request authorization: %w", err)
+		return nil, fmt.Errorf("failed to request authorization: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -67,14 +78,15 @@ func getOAuthToken(server, username, password string) (string, error) {
 			if err == nil && parsedURL.Fragment != "" {
 				values, _ := url.ParseQuery(parsedURL.Fragment)
 				if token := values.Get("access_token"); token != "" {
-					return token, nil
+					expiresIn, _ := strconv.Atoi(values.Get("expires_in"))
+					return newToken(tokenResponse{AccessToken: token, ExpiresIn: expiresIn}), nil
 				}
 			}
 		}
 	}
 
 	// If challenge-response didn't work, try direct token endpoint
-	tokenURL := fmt.Sprintf("%s/oauth/token", strings.TrimSuffix(server, "/"))
+	tokenURL := metadata.TokenEndpoint
 	data := url.Values{}
 	data.Set("grant_type", "password")
 	data.Set("username", username)
@@ -83,7 +95,7 @@ func getOAuthToken(server, username, password string) (string, error) {
 
 	req2, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("failed to create token request: %w", err)
+		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 
 	req2.Header.Set("Authorization", "Basic "+auth)
@@ -92,40 +104,32 @@ func getOAuthToken(server, username, password string) (string, error) {
 
 	resp2, err := client.Do(req2)
 	if err != nil {
-		return "", fmt.Errorf("failed to request token: %w", err)
+		return nil, fmt.Errorf("failed to request token: %w", err)
 	}
 	defer resp2.Body.Close()
 
 	if resp2.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp2.Body)
-		return "", fmt.Errorf("token request failed with status %d: %s", resp2.StatusCode, string(body))
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp2.StatusCode, string(body))
 	}
 
 	// Parse the response
 	var tokenResp tokenResponse
 	if err := json.NewDecoder(resp2.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", fmt.Errorf("no access token in response")
+		return nil, fmt.Errorf("no access token in response")
 	}
 
-	return tokenResp.AccessToken, nil
+	return newToken(tokenResp), nil
 }
 
-// tryKubeconfig attempts to load config from kubeconfig file
-func tryKubeconfig() (*rest.Config, error) {
-	// Try KUBECONFIG environment variable first
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		// Default to ~/.kube/config
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		kubeconfig = filepath.Join(home, ".kube", "config")
-	}
+// tryKubeconfig attempts to load config from kubeconfig file, using the given
+// context or the current context when it is empty
+func tryKubeconfig(context string) (*rest.Config, error) {
+	kubeconfig := auth.KubeconfigPath()
 
 	// Check if file exists
 	if _, err := os.Stat(kubeconfig); os.IsNotExist(err) {
@@ -133,96 +137,118 @@ func tryKubeconfig() (*rest.Config, error) {
 	}
 
 	// Load kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	// Set insecure if needed
-	config.TLSClientConfig.Insecure = true
-
 	return config, nil
 }
 
-// CreateClient creates a Kubernetes client using username/password authentication
-// First tries to use kubeconfig if available, otherwise falls back to OAuth token
-func CreateClient(server, username, password string) (*kubernetes.Clientset, error) {
-	var config *rest.Config
-	var err error
-
-	// First, try to use kubeconfig (preferred method, works with oc login)
-	config, err = tryKubeconfig()
-	if err == nil {
-		// Successfully loaded from kubeconfig
-		clientset, err := kubernetes.NewForConfig(config)
-		if err == nil {
-			return clientset, nil
+// GetRESTConfig returns the REST config used to build the session clients for
+// the authentication method chosen in the auth config.
+// For OAuth and OIDC logins the returned TokenSource caches the token on disk and
+// logs in again whenever the API rejects it; it is nil for the other methods.
+func GetRESTConfig(authConfig *auth.Config) (*rest.Config, *TokenSource, error) {
+	switch authConfig.Method {
+	case auth.MethodKubeconfig:
+		config, err := tryKubeconfig(authConfig.KubeContext)
+		if err != nil {
+			return nil, nil, err
 		}
-		// If kubeconfig load failed, fall through to username/password
-	}
+		applyTLSOverrides(config, authConfig)
+		return config, nil, nil
 
-	// Fall back to username/password OAuth flow
-	if username == "" || password == "" {
-		return nil, fmt.Errorf("kubeconfig not available and username/password not provided")
-	}
+	case auth.MethodInCluster:
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+		applyTLSOverrides(config, authConfig)
+		return config, nil, nil
 
-	// Get an OAuth token using username/password
-	token, err := getOAuthToken(server, username, password)
-	if err != nil {
-		return nil, fmt.Errorf("failed to obtain OAuth token: %w", err)
-	}
+	case auth.MethodToken:
+		tlsConfig := tlsClientConfigFor(authConfig.Server, authConfig)
+		if tlsConfig.Insecure {
+			warnInsecure()
+		}
+		// client-go re-reads BearerTokenFile periodically, so rotated tokens are picked up
+		return &rest.Config{
+			Host:            authConfig.Server,
+			BearerToken:     authConfig.Token,
+			BearerTokenFile: authConfig.TokenFile,
+			TLSClientConfig: tlsConfig,
+		}, nil, nil
 
-	fmt.Printf("Bearer token (from OAuth): %s\n", token)
+	case auth.MethodOIDC:
+		return oidcRESTConfig(authConfig)
 
-	// Create REST config with Bearer token authentication
-	config = &rest.Config{
-		Host:        server,
-		BearerToken: token,
-		TLSClientConfig: rest.TLSClientConfig{
-			Insecure: true, // Accept unsigned/self-signed certificates
-		},
-	}
+	case auth.MethodPassword:
+		return passwordRESTConfig(authConfig)
 
-	// Create the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	default:
+		return nil, nil, fmt.Errorf("unsupported authentication method %q", authConfig.Method)
 	}
-
-	return clientset, nil
 }
 
-// GetRESTConfig returns the REST config used by the client
-// This is a helper to create dynamic clients for OpenShift-specific resources
-func GetRESTConfig(server, username, password string) (*rest.Config, error) {
-	var config *rest.Config
-	var err error
+// passwordRESTConfig logs in to the OpenShift OAuth server with username/password
+func passwordRESTConfig(authConfig *auth.Config) (*rest.Config, *TokenSource, error) {
+	server, username, password := authConfig.Server, authConfig.Username, authConfig.Password
 
-	// First, try to use kubeconfig (preferred method, works with oc login)
-	config, err = tryKubeconfig()
-	if err == nil {
-		return config, nil
+	if username == "" {
+		return nil, nil, fmt.Errorf("username not provided")
 	}
 
-	// Fall back to username/password OAuth flow
-	if username == "" || password == "" {
-		return nil, fmt.Errorf("kubeconfig not available and username/password not provided")
+	tlsConfig := tlsClientConfigFor(server, authConfig)
+	if tlsConfig.Insecure {
+		warnInsecure()
 	}
 
-	// Get an OAuth token using username/password
-	token, err := getOAuthToken(server, username, password)
-	if err != nil {
-		return nil, fmt.Errorf("failed to obtain OAuth token: %w", err)
+	// Reuse a cached token or log in using username/password
+	tokens := newTokenSource(server, username,
+		func(interactive bool) (*Token, error) {
+			// Only ask for the password when a login is actually needed
+			if password == "" {
+				if authConfig.PromptPassword == nil {
+					return nil, fmt.Errorf("password not provided")
+				}
+				if !interactive {
+					return nil, ErrLoginRequired
+				}
+				prompted, err := authConfig.PromptPassword()
+				if err != nil {
+					return nil, err
+				}
+				password = prompted
+			}
+			return getOAuthToken(server, username, password, tlsConfig)
+		},
+		func(refreshToken string) (*Token, error) {
+			return refreshOAuthToken(server, refreshToken, tlsConfig)
+		},
+	)
+	if _, err := tokens.Token(); err != nil {
+		return nil, nil, err
 	}
 
-	// Create REST config with Bearer token authentication
-	config = &rest.Config{
-		Host:        server,
-		BearerToken: token,
-		TLSClientConfig: rest.TLSClientConfig{
-			Insecure: true, // Accept unsigned/self-signed certificates
-		},
+	config := bearerRESTConfig(server, tlsConfig, tokens)
+	tokens.revoke = func(token *Token) error {
+		return revokeOAuthToken(config, token.AccessToken)
 	}
 
-	return config, nil
+	return config, tokens, nil
+}
+
+// bearerRESTConfig creates a REST config whose transport injects the current bearer token
+func bearerRESTConfig(server string, tlsConfig rest.TLSClientConfig, tokens *TokenSource) *rest.Config {
+	return &rest.Config{
+		Host:            server,
+		TLSClientConfig: tlsConfig,
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			return &reauthRoundTripper{source: tokens, next: rt}
+		},
+	}
 }
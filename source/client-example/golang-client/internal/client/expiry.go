@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/oidc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+// BearerToken returns the token a REST config authenticates with, reading
+// BearerTokenFile when set. It returns "" for client certificate credentials.
+func BearerToken(config *rest.Config) (string, error) {
+	if config.BearerTokenFile != "" {
+		data, err := os.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return config.BearerToken, nil
+}
+
+// TokenExpiry reports when a bearer token expires. JWTs (OIDC and service
+// account tokens) carry an exp claim; OpenShift OAuth tokens are looked up as
+// UserOAuthAccessToken objects. ok is false when the token does not expire or
+// its expiry cannot be determined.
+func TokenExpiry(ctx context.Context, config *rest.Config, token string) (expiry time.Time, ok bool, err error) {
+	if expiry, ok := oidc.JWTExpiry(token); ok {
+		return expiry, true, nil
+	}
+
+	name, err := oauthAccessTokenName(token)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+
+	dynamicClient, err := tokenClient(config, token)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	object, err := dynamicClient.Resource(userOAuthAccessTokenResource).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to look up OAuth access token: %w", err)
+	}
+
+	// expiresIn is in seconds from creation; 0 means the token never expires
+	expiresIn, _, _ := unstructured.NestedInt64(object.Object, "expiresIn")
+	if expiresIn <= 0 {
+		return time.Time{}, false, nil
+	}
+
+	return object.GetCreationTimestamp().Add(time.Duration(expiresIn) * time.Second), true, nil
+}
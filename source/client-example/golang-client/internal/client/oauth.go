@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// oauthMetadataPath is where OpenShift advertises its OAuth server (RFC 8414)
+const oauthMetadataPath = "/.well-known/oauth-authorization-server"
+
+// oauthMetadata holds the endpoints advertised by the OAuth server
+type oauthMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// discoverOAuthServer fetches the OAuth server metadata from the API server.
+// On OpenShift the OAuth server runs behind its own route, so the authorize and
+// token endpoints must be looked up rather than derived from the API URL.
+func discoverOAuthServer(client *http.Client, server string) (*oauthMetadata, error) {
+	metadataURL := strings.TrimSuffix(server, "/") + oauthMetadataPath
+
+	req, err := http.NewRequest("GET", metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OAuth discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OAuth server discovery failed: could not reach %s: %w", metadataURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("OAuth server discovery failed: %s returned 404 (is this an OpenShift cluster with the integrated OAuth server enabled?)", metadataURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OAuth server discovery failed: %s returned status %d: %s", metadataURL, resp.StatusCode, string(body))
+	}
+
+	var metadata oauthMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("OAuth server discovery failed: invalid metadata from %s: %w", metadataURL, err)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OAuth server discovery failed: %s did not advertise authorization and token endpoints", metadataURL)
+	}
+
+	return &metadata, nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+	"github.com/bryon/ocp-lister/internal/oidc"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// oidcLoginTimeout bounds a whole login, including the time the user takes
+	// in the browser or on the other device
+	oidcLoginTimeout = 10 * time.Minute
+	// oidcRefreshTimeout bounds a token refresh
+	oidcRefreshTimeout = 30 * time.Second
+)
+
+// oidcRESTConfig logs in through an OpenID Connect provider (e.g. Keycloak) and
+// returns a REST config that presents the resulting token to the cluster
+func oidcRESTConfig(authConfig *auth.Config) (*rest.Config, *TokenSource, error) {
+	providerTLS := oidcTLSClientConfig(authConfig)
+	httpClient, err := newHTTPClient(authConfig.OIDCIssuerURL, providerTLS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oidcConfig := &oidc.Config{
+		IssuerURL:    authConfig.OIDCIssuerURL,
+		ClientID:     authConfig.OIDCClientID,
+		ClientSecret: authConfig.OIDCClientSecret,
+		Scopes:       authConfig.OIDCScopes,
+		CallbackPort: authConfig.OIDCCallbackPort,
+		HTTPClient:   httpClient,
+	}
+
+	login := func(interactive bool) (*Token, error) {
+		// The browser and device code flows both need the user
+		if !interactive {
+			return nil, ErrLoginRequired
+		}
+
+		ctx, cancel := interruptible(oidcLoginTimeout)
+		defer cancel()
+
+		var tokens *oidc.Tokens
+		var err error
+		if authConfig.OIDCFlow == auth.OIDCFlowDevice {
+			tokens, err = oidc.LoginWithDeviceCode(ctx, oidcConfig)
+		} else {
+			tokens, err = oidc.LoginWithBrowser(ctx, oidcConfig)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("OIDC login failed: %w", err)
+		}
+		return fromOIDCTokens(tokens), nil
+	}
+
+	refresh := func(refreshToken string) (*Token, error) {
+		ctx, cancel := interruptible(oidcRefreshTimeout)
+		defer cancel()
+
+		tokens, err := oidc.Refresh(ctx, oidcConfig, refreshToken)
+		if err != nil {
+			return nil, err
+		}
+		return fromOIDCTokens(tokens), nil
+	}
+
+	tlsConfig := tlsClientConfigFor(authConfig.Server, authConfig)
+	if tlsConfig.Insecure {
+		warnInsecure()
+	}
+
+	identity := "oidc:" + authConfig.OIDCIssuerURL + "#" + authConfig.OIDCClientID
+	tokens := newTokenSource(authConfig.Server, identity, login, refresh)
+	tokens.revoke = func(token *Token) error {
+		// ID tokens cannot be revoked; ending the session via the refresh token stops renewal
+		if token.RefreshToken == "" {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), revokeTimeout)
+		defer cancel()
+		return oidc.RevokeRefreshToken(ctx, oidcConfig, token.RefreshToken)
+	}
+	if _, err := tokens.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return bearerRESTConfig(authConfig.Server, tlsConfig, tokens), tokens, nil
+}
+
+// interruptible returns a context that Ctrl-C cancels and that ends after the
+// timeout, so a stuck provider cannot hang a login that happens outside an
+// operation, e.g. at startup
+func interruptible(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// fromOIDCTokens converts provider tokens into the token presented to the cluster
+func fromOIDCTokens(tokens *oidc.Tokens) *Token {
+	return &Token{
+		AccessToken:  tokens.BearerToken(),
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// sha256TokenPrefix marks OpenShift OAuth access tokens
+const sha256TokenPrefix = "sha256~"
+
+// revokeTimeout bounds the revocation request so exiting never hangs
+const revokeTimeout = 10 * time.Second
+
+// userOAuthAccessTokenResource is the GVR that lets users delete their own tokens
+var userOAuthAccessTokenResource = schema.GroupVersionResource{
+	Group:    "oauth.openshift.io",
+	Version:  "v1",
+	Resource: "useroauthaccesstokens",
+}
+
+// oauthAccessTokenName returns the name of the OAuthAccessToken object for a token.
+// OpenShift stores only a hash: "sha256~" + base64url(sha256(token without prefix)).
+func oauthAccessTokenName(token string) (string, error) {
+	if !strings.HasPrefix(token, sha256TokenPrefix) {
+		return "", fmt.Errorf("token is not an OpenShift OAuth access token")
+	}
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(token, sha256TokenPrefix)))
+	return sha256TokenPrefix + base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// tokenClient returns a dynamic client that authenticates as ourselves with
+// exactly this token: no impersonation, and no re-login transport, so a
+// rejected token can never trigger a new login
+func tokenClient(config *rest.Config, token string) (dynamic.Interface, error) {
+	tokenConfig := rest.CopyConfig(config)
+	tokenConfig.Impersonate = rest.ImpersonationConfig{}
+	tokenConfig.WrapTransport = nil
+	tokenConfig.BearerToken = token
+	tokenConfig.BearerTokenFile = ""
+
+	dynamicClient, err := dynamic.NewForConfig(tokenConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return dynamicClient, nil
+}
+
+// revokeOAuthToken deletes the OAuthAccessToken backing the token, so it can no
+// longer be used even if it was copied from the cache or terminal scrollback
+func revokeOAuthToken(config *rest.Config, token string) error {
+	name, err := oauthAccessTokenName(token)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := tokenClient(config, token)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), revokeTimeout)
+	defer cancel()
+
+	err = dynamicClient.Resource(userOAuthAccessTokenResource).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsUnauthorized(err) {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// warnInsecure prints a prominent warning when certificate verification is disabled
+func warnInsecure() {
+	fmt.Fprintln(os.Stderr, strings.Repeat("!", 70))
+	fmt.Fprintln(os.Stderr, "⚠️  WARNING: TLS certificate verification is DISABLED.")
+	fmt.Fprintln(os.Stderr, "   Credentials and tokens can be intercepted by anyone on the network path.")
+	fmt.Fprintln(os.Stderr, "   Never use this option against production clusters.")
+	fmt.Fprintln(os.Stderr, strings.Repeat("!", 70))
+}
+
+// applyTLSOverrides applies the CA bundle or insecure opt-in from the auth config.
+// A kubeconfig's own certificate-authority(-data) is kept unless explicitly overridden.
+func applyTLSOverrides(config *rest.Config, authConfig *auth.Config) {
+	switch {
+	case authConfig.InsecureSkipTLSVerify:
+		config.TLSClientConfig.Insecure = true
+		// client-go rejects a CA combined with the insecure flag
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = nil
+	case authConfig.CAFile != "":
+		config.TLSClientConfig.CAFile = authConfig.CAFile
+		config.TLSClientConfig.CAData = nil
+	}
+
+	if config.TLSClientConfig.Insecure {
+		warnInsecure()
+	}
+}
+
+// tlsClientConfigFor builds the TLS settings for a server that is not loaded from kubeconfig.
+// It uses the CA bundle file if set, otherwise the certificate-authority(-data) of a
+// kubeconfig cluster entry pointing at the same server, otherwise the system roots.
+func tlsClientConfigFor(server string, authConfig *auth.Config) rest.TLSClientConfig {
+	tlsConfig := rest.TLSClientConfig{}
+
+	switch {
+	case authConfig.InsecureSkipTLSVerify:
+		tlsConfig.Insecure = true
+	case authConfig.CAFile != "":
+		tlsConfig.CAFile = authConfig.CAFile
+	default:
+		tlsConfig.CAFile, tlsConfig.CAData = kubeconfigCA(server, authConfig.KubeContext)
+	}
+
+	return tlsConfig
+}
+
+// oidcTLSClientConfig builds the TLS settings for the OIDC issuer. The cluster
+// CA bundle is not used: the issuer is usually signed by a different CA.
+func oidcTLSClientConfig(authConfig *auth.Config) rest.TLSClientConfig {
+	if authConfig.InsecureSkipTLSVerify {
+		return rest.TLSClientConfig{Insecure: true}
+	}
+	return rest.TLSClientConfig{CAFile: authConfig.OIDCCAFile}
+}
+
+// kubeconfigCA looks up the CA of a kubeconfig cluster whose server matches.
+// It reads the same file as the kubeconfig method and prefers the cluster of
+// the selected context, falling back to any other cluster with that server.
+func kubeconfigCA(server, kubeContext string) (string, []byte) {
+	rawConfig, err := clientcmd.LoadFromFile(auth.KubeconfigPath())
+	if err != nil {
+		return "", nil
+	}
+	// Make a relative certificate-authority path relative to the kubeconfig
+	if err := clientcmd.ResolveLocalPaths(rawConfig); err != nil {
+		return "", nil
+	}
+
+	matches := func(cluster *clientcmdapi.Cluster) bool {
+		return cluster != nil && strings.TrimSuffix(cluster.Server, "/") == strings.TrimSuffix(server, "/")
+	}
+
+	if kubeContext == "" {
+		kubeContext = rawConfig.CurrentContext
+	}
+	if context, ok := rawConfig.Contexts[kubeContext]; ok {
+		if cluster := rawConfig.Clusters[context.Cluster]; matches(cluster) {
+			return cluster.CertificateAuthority, cluster.CertificateAuthorityData
+		}
+	}
+
+	for _, cluster := range rawConfig.Clusters {
+		if matches(cluster) {
+			return cluster.CertificateAuthority, cluster.CertificateAuthorityData
+		}
+	}
+
+	return "", nil
+}
+
+// httpTimeout bounds each request to an OAuth or OIDC server, so a stuck
+// server cannot hang a login or token refresh
+const httpTimeout = 30 * time.Second
+
+// newHTTPClient creates an HTTP client that verifies the server using the given TLS settings
+func newHTTPClient(server string, tlsConfig rest.TLSClientConfig) (*http.Client, error) {
+	tlsClientConfig, err := rest.TLSConfigFor(&rest.Config{Host: server, TLSClientConfig: tlsConfig})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build TLS configuration: %w", err)
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsClientConfig,
+		},
+		Timeout: httpTimeout,
+	}, nil
+}
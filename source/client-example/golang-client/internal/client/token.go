@@ -0,0 +1,275 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// expirySkew treats tokens as expired slightly early to avoid racing the server
+const expirySkew = time.Minute
+
+// Token is an OAuth access token together with its lifetime
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// newToken converts an OAuth token response into a Token with an absolute expiry
+func newToken(resp tokenResponse) *Token {
+	token := &Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+	}
+	if resp.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token
+}
+
+// Expired reports whether the token has expired (or is about to)
+func (t *Token) Expired() bool {
+	if t == nil || t.AccessToken == "" {
+		return true
+	}
+	if t.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Now().Add(expirySkew).After(t.ExpiresAt)
+}
+
+// ErrLoginRequired is returned by API requests whose token lapsed when logging
+// in again would have to prompt the user
+var ErrLoginRequired = errors.New("login required: the token expired and logging in again needs your input; run ocp-lister again")
+
+// loginFunc obtains a fresh token. Only an interactive login may prompt the
+// user; otherwise a login that needs input returns ErrLoginRequired.
+type loginFunc func(interactive bool) (*Token, error)
+
+// refreshFunc exchanges a refresh token for a new token
+type refreshFunc func(refreshToken string) (*Token, error)
+
+// revokeFunc invalidates a token on the server that issued it
+type revokeFunc func(token *Token) error
+
+// TokenSource hands out the current token and logs in again when it lapses.
+// Tokens are persisted to the on-disk cache so they survive across runs.
+type TokenSource struct {
+	mu       sync.Mutex
+	server   string
+	identity string
+	login    loginFunc
+	refresh  refreshFunc
+	revoke   revokeFunc
+	token    *Token
+}
+
+// newTokenSource creates a token source for the given server and identity
+// (username or OIDC client), seeding it from the on-disk cache when possible
+func newTokenSource(server, identity string, login loginFunc, refresh refreshFunc) *TokenSource {
+	source := &TokenSource{
+		server:   server,
+		identity: identity,
+		login:    login,
+		refresh:  refresh,
+	}
+
+	if cached, err := loadCachedToken(server, identity); err == nil && !cached.Expired() {
+		source.token = cached
+	}
+
+	return source
+}
+
+// Token returns a valid access token, logging in if there is none or it has
+// expired. The login may prompt the user.
+func (s *TokenSource) Token() (string, error) {
+	return s.get(true)
+}
+
+// get returns a valid access token, logging in if there is none or it has expired
+func (s *TokenSource) get(interactive bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.token.Expired() {
+		return s.token.AccessToken, nil
+	}
+	if err := s.renewLocked(interactive); err != nil {
+		return "", err
+	}
+	return s.token.AccessToken, nil
+}
+
+// Current returns the token currently held without triggering a login
+func (s *TokenSource) Current() *Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil
+	}
+	token := *s.token
+	return &token
+}
+
+// Revoke invalidates the current token on the server and removes it from the
+// on-disk cache. Any later request logs in again.
+func (s *TokenSource) Revoke() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.token != nil && s.revoke != nil {
+		err = s.revoke(s.token)
+	}
+	s.token = nil
+
+	if cacheErr := deleteCachedToken(s.server, s.identity); cacheErr != nil && err == nil {
+		err = cacheErr
+	}
+
+	return err
+}
+
+// Invalidate re-acquires the token after the server rejected it, without
+// prompting the user. If another request has already replaced the rejected
+// token, that one is reused.
+func (s *TokenSource) Invalidate(rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken != rejected && !s.token.Expired() {
+		return s.token.AccessToken, nil
+	}
+	if err := s.renewLocked(false); err != nil {
+		return "", err
+	}
+	return s.token.AccessToken, nil
+}
+
+// renewLocked obtains a new token via refresh grant or a new login. Caller holds s.mu.
+func (s *TokenSource) renewLocked(interactive bool) error {
+	var token *Token
+	var err error
+
+	if s.refresh != nil && s.token != nil && s.token.RefreshToken != "" {
+		token, err = s.refresh(s.token.RefreshToken)
+	}
+	if token == nil {
+		token, err = s.login(interactive)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to obtain token: %w", err)
+	}
+
+	s.token = token
+	if err := saveCachedToken(s.server, s.identity, token); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cache token: %v\n", err)
+	}
+
+	return nil
+}
+
+// refreshOAuthToken exchanges a refresh token for a new access token
+func refreshOAuthToken(server, refreshToken string, tlsConfig rest.TLSClientConfig) (*Token, error) {
+	client, err := newHTTPClient(server, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := discoverOAuthServer(client, server)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL := metadata.TokenEndpoint
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("client_id", "openshift-challenging-client")
+
+	resp, err := client.PostForm(tokenURL, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	return newToken(tokenResp), nil
+}
+
+// reauthRoundTripper sets the bearer token on each request and, when the API
+// answers 401 Unauthorized, logs in again and retries the request once. It
+// never prompts: a login that needs the user fails with ErrLoginRequired.
+type reauthRoundTripper struct {
+	source *TokenSource
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *reauthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.source.get(false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.next.RoundTrip(withBearer(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// A request body can only be replayed if it can be recreated
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	newToken, err := rt.source.Invalidate(token)
+	if err != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("token was rejected and re-login failed: %w", err)
+	}
+
+	retry := withBearer(req, newToken)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return rt.next.RoundTrip(retry)
+}
+
+// withBearer returns a copy of the request carrying the given bearer token
+func withBearer(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
@@ -0,0 +1,271 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTransport answers with the given status codes in turn and records the
+// bearer token and body of every request
+type fakeTransport struct {
+	statuses []int
+	tokens   []string
+	bodies   []string
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.tokens = append(f.tokens, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	var body string
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	f.bodies = append(f.bodies, body)
+
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestReauthRoundTripper(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         *Token
+		statuses      []int
+		loginErr      error
+		prompts       bool
+		body          string
+		replayable    bool
+		wantStatus    int
+		wantErr       bool
+		wantLogins    int
+		wantRefresh   int
+		wantTokens    []string
+		wantRetryBody string
+	}{
+		{
+			name:       "accepted",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantTokens: []string{"old"},
+		},
+		{
+			name:       "rejected token logs in once and retries",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusUnauthorized, http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantLogins: 1,
+			wantTokens: []string{"old", "new"},
+		},
+		{
+			name:       "rejected again is returned",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusUnauthorized, http.StatusUnauthorized},
+			wantStatus: http.StatusUnauthorized,
+			wantLogins: 1,
+			wantTokens: []string{"old", "new"},
+		},
+		{
+			name:        "refresh token is used first",
+			token:       &Token{AccessToken: "old", RefreshToken: "refresh"},
+			statuses:    []int{http.StatusUnauthorized, http.StatusOK},
+			wantStatus:  http.StatusOK,
+			wantRefresh: 1,
+			wantTokens:  []string{"old", "refreshed"},
+		},
+		{
+			name:       "failed login",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusUnauthorized},
+			loginErr:   errors.New("bad password"),
+			wantErr:    true,
+			wantLogins: 1,
+			wantTokens: []string{"old"},
+		},
+		{
+			name:       "no token logs in before the request",
+			statuses:   []int{http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantLogins: 1,
+			wantTokens: []string{"new"},
+		},
+		{
+			name:       "rejected token is not replaced by prompting",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusUnauthorized},
+			prompts:    true,
+			wantErr:    true,
+			wantLogins: 1,
+			wantTokens: []string{"old"},
+		},
+		{
+			name:       "expired token is not replaced by prompting",
+			token:      &Token{AccessToken: "old", ExpiresAt: time.Now().Add(-time.Hour)},
+			statuses:   []int{http.StatusOK},
+			prompts:    true,
+			wantErr:    true,
+			wantLogins: 1,
+		},
+		{
+			name:          "replayable body is sent again",
+			token:         &Token{AccessToken: "old"},
+			statuses:      []int{http.StatusUnauthorized, http.StatusOK},
+			body:          `{"kind":"Namespace"}`,
+			replayable:    true,
+			wantStatus:    http.StatusOK,
+			wantLogins:    1,
+			wantTokens:    []string{"old", "new"},
+			wantRetryBody: `{"kind":"Namespace"}`,
+		},
+		{
+			name:       "body that cannot be replayed is not retried",
+			token:      &Token{AccessToken: "old"},
+			statuses:   []int{http.StatusUnauthorized, http.StatusOK},
+			body:       `{"kind":"Namespace"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantTokens: []string{"old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempCache(t)
+
+			var logins, refreshes int
+			source := &TokenSource{
+				server:   "https://api.example.com:6443",
+				identity: "alice",
+				token:    tt.token,
+				login: func(interactive bool) (*Token, error) {
+					logins++
+					if interactive {
+						t.Error("RoundTrip() logged in interactively")
+					}
+					if tt.prompts && !interactive {
+						return nil, ErrLoginRequired
+					}
+					if tt.loginErr != nil {
+						return nil, tt.loginErr
+					}
+					return &Token{AccessToken: "new"}, nil
+				},
+				refresh: func(refreshToken string) (*Token, error) {
+					refreshes++
+					return &Token{AccessToken: "refreshed"}, nil
+				},
+			}
+			next := &fakeTransport{statuses: tt.statuses}
+			rt := &reauthRoundTripper{source: source, next: next}
+
+			req, err := http.NewRequest(http.MethodPost, "https://api.example.com:6443/api/v1/namespaces", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body != "" {
+				req.Body = io.NopCloser(strings.NewReader(tt.body))
+				if tt.replayable {
+					req.GetBody = func() (io.ReadCloser, error) {
+						return io.NopCloser(bytes.NewReader([]byte(tt.body))), nil
+					}
+				}
+			}
+
+			resp, err := rt.RoundTrip(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.prompts && !errors.Is(err, ErrLoginRequired) {
+				t.Errorf("RoundTrip() error = %v, want %v", err, ErrLoginRequired)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("RoundTrip() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if logins != tt.wantLogins {
+				t.Errorf("logins = %d, want %d", logins, tt.wantLogins)
+			}
+			if refreshes != tt.wantRefresh {
+				t.Errorf("refreshes = %d, want %d", refreshes, tt.wantRefresh)
+			}
+			if !reflect.DeepEqual(next.tokens, tt.wantTokens) {
+				t.Errorf("tokens sent = %q, want %q", next.tokens, tt.wantTokens)
+			}
+			if tt.wantRetryBody != "" && next.bodies[len(next.bodies)-1] != tt.wantRetryBody {
+				t.Errorf("retried body = %q, want %q", next.bodies[len(next.bodies)-1], tt.wantRetryBody)
+			}
+			if req.Header.Get("Authorization") != "" {
+				t.Errorf("RoundTrip() modified the caller's request")
+			}
+		})
+	}
+}
+
+func TestInvalidateReusesReplacedToken(t *testing.T) {
+	useTempCache(t)
+
+	var logins int
+	source := &TokenSource{
+		server:   "https://api.example.com:6443",
+		identity: "alice",
+		token:    &Token{AccessToken: "replaced"},
+		login: func(interactive bool) (*Token, error) {
+			logins++
+			return &Token{AccessToken: "new"}, nil
+		},
+	}
+
+	// Another request already logged in again after "old" was rejected
+	token, err := source.Invalidate("old")
+	if err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	if token != "replaced" || logins != 0 {
+		t.Errorf("Invalidate() = %q after %d logins, want the replaced token without a login", token, logins)
+	}
+
+	// The current token itself was rejected
+	token, err = source.Invalidate("replaced")
+	if err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	if token != "new" || logins != 1 {
+		t.Errorf("Invalidate() = %q after %d logins, want a new token after one login", token, logins)
+	}
+
+	// The new token is cached for the next run
+	if cached, err := loadCachedToken(source.server, source.identity); err != nil || cached.AccessToken != "new" {
+		t.Errorf("cached token = %v, %v; want new", cached, err)
+	}
+}
+
+func TestTokenLogsInInteractively(t *testing.T) {
+	useTempCache(t)
+
+	var interactiveLogins int
+	source := &TokenSource{
+		server:   "https://api.example.com:6443",
+		identity: "alice",
+		login: func(interactive bool) (*Token, error) {
+			if interactive {
+				interactiveLogins++
+			}
+			return &Token{AccessToken: "new"}, nil
+		},
+	}
+
+	// Token is called when connecting, where prompting for the password is fine
+	if token, err := source.Token(); err != nil || token != "new" {
+		t.Fatalf("Token() = %q, %v; want new", token, err)
+	}
+	if interactiveLogins != 1 {
+		t.Errorf("interactive logins = %d, want 1", interactiveLogins)
+	}
+}
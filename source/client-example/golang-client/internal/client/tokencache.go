@@ -0,0 +1,109 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cachedToken is the on-disk representation of a token for one server/user pair
+type cachedToken struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Token
+}
+
+// tokenCacheDir returns the directory holding cached tokens
+func tokenCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(dir, "ocp-lister", "tokens"), nil
+}
+
+// tokenCachePath returns the cache file for a server/user pair
+func tokenCachePath(server, username string) (string, error) {
+	dir, err := tokenCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(server + "\n" + username))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// loadCachedToken reads the cached token for a server/user pair
+func loadCachedToken(server, username string) (*Token, error) {
+	path, err := tokenCachePath(server, username)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedToken
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to decode cached token: %w", err)
+	}
+	if cached.Server != server || cached.Username != username {
+		return nil, fmt.Errorf("cached token belongs to a different server or user")
+	}
+
+	return &cached.Token, nil
+}
+
+// saveCachedToken writes the token with owner-only permissions
+func saveCachedToken(server, username string, token *Token) error {
+	path, err := tokenCachePath(server, username)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cachedToken{Server: server, Username: username, Token: *token})
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated token
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to create token cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set token cache permissions: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// deleteCachedToken removes the cached token for a server/user pair
+func deleteCachedToken(server, username string) error {
+	path, err := tokenCachePath(server, username)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cached token: %w", err)
+	}
+	return nil
+}
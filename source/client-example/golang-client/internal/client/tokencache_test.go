@@ -0,0 +1,200 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTempCache points the token cache at a fresh directory for the test
+func useTempCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	return filepath.Join(dir, "ocp-lister", "tokens")
+}
+
+func TestSaveCachedToken(t *testing.T) {
+	dir := useTempCache(t)
+	server, user := "https://api.example.com:6443", "alice"
+	token := &Token{AccessToken: "sha256~abc", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour).Round(time.Second)}
+
+	if err := saveCachedToken(server, user, token); err != nil {
+		t.Fatalf("saveCachedToken() error = %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("cache directory permissions = %o, want 700", perm)
+	}
+
+	path, err := tokenCachePath(server, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("token file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("token file permissions = %o, want 600", perm)
+	}
+
+	loaded, err := loadCachedToken(server, user)
+	if err != nil {
+		t.Fatalf("loadCachedToken() error = %v", err)
+	}
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken || !loaded.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("loadCachedToken() = %+v, want %+v", loaded, token)
+	}
+}
+
+func TestSaveCachedTokenReplacesAtomically(t *testing.T) {
+	dir := useTempCache(t)
+	server, user := "https://api.example.com:6443", "alice"
+
+	for _, access := range []string{"first", "second", "third"} {
+		if err := saveCachedToken(server, user, &Token{AccessToken: access}); err != nil {
+			t.Fatalf("saveCachedToken(%s) error = %v", access, err)
+		}
+	}
+
+	loaded, err := loadCachedToken(server, user)
+	if err != nil {
+		t.Fatalf("loadCachedToken() error = %v", err)
+	}
+	if loaded.AccessToken != "third" {
+		t.Errorf("loadCachedToken() = %q, want the last token saved", loaded.AccessToken)
+	}
+
+	// The temporary files are renamed into place or removed, never left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".token-") {
+			t.Errorf("temporary file %s left in the cache", entry.Name())
+		}
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache holds %d files, want 1", len(entries))
+	}
+}
+
+func TestLoadCachedToken(t *testing.T) {
+	server, user := "https://api.example.com:6443", "alice"
+
+	tests := []struct {
+		name string
+		// setup prepares the cache file of server/user
+		setup   func(t *testing.T, path string)
+		server  string
+		user    string
+		wantErr string
+	}{
+		{
+			name:   "cached",
+			setup:  func(t *testing.T, path string) { saveOrFail(t, server, user) },
+			server: server, user: user,
+		},
+		{
+			name:   "other user",
+			setup:  func(t *testing.T, path string) { saveOrFail(t, server, user) },
+			server: server, user: "bob",
+			wantErr: "no such file",
+		},
+		{
+			name:   "other server",
+			setup:  func(t *testing.T, path string) { saveOrFail(t, server, user) },
+			server: "https://other.example.com:6443", user: user,
+			wantErr: "no such file",
+		},
+		{
+			name: "file of another identity",
+			setup: func(t *testing.T, path string) {
+				writeOrFail(t, path, `{"server":"https://other.example.com:6443","username":"alice","access_token":"x"}`)
+			},
+			server: server, user: user,
+			wantErr: "different server or user",
+		},
+		{
+			name:   "corrupt file",
+			setup:  func(t *testing.T, path string) { writeOrFail(t, path, "{not json") },
+			server: server, user: user,
+			wantErr: "failed to decode cached token",
+		},
+		{
+			name:   "nothing cached",
+			setup:  func(t *testing.T, path string) {},
+			server: server, user: user,
+			wantErr: "no such file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempCache(t)
+			path, err := tokenCachePath(server, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(t, path)
+
+			token, err := loadCachedToken(tt.server, tt.user)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadCachedToken() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCachedToken() error = %v", err)
+			}
+			if token.AccessToken != "cached" {
+				t.Errorf("loadCachedToken() = %q, want cached", token.AccessToken)
+			}
+		})
+	}
+}
+
+func TestDeleteCachedToken(t *testing.T) {
+	useTempCache(t)
+	server, user := "https://api.example.com:6443", "alice"
+
+	// Deleting a token that was never cached is not an error
+	if err := deleteCachedToken(server, user); err != nil {
+		t.Fatalf("deleteCachedToken() without a cache error = %v", err)
+	}
+
+	saveOrFail(t, server, user)
+	if err := deleteCachedToken(server, user); err != nil {
+		t.Fatalf("deleteCachedToken() error = %v", err)
+	}
+	if _, err := loadCachedToken(server, user); !os.IsNotExist(err) {
+		t.Errorf("loadCachedToken() after delete error = %v, want not exist", err)
+	}
+}
+
+func saveOrFail(t *testing.T, server, user string) {
+	t.Helper()
+	if err := saveCachedToken(server, user, &Token{AccessToken: "cached"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeOrFail(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
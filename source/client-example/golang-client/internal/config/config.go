@@ -0,0 +1,328 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+)
+
+// EnvPrefix is prepended to every environment variable read by the tool
+const EnvPrefix = "OCP_LISTER_"
+
+// Defaults used when no flag, environment variable or profile sets a value
+const (
+	DefaultModelNamespace   = "llm"
+	DefaultGatewayName      = "maas-default-gateway"
+	DefaultGatewayNamespace = "openshift-ingress"
+	DefaultTimeout          = 30 * time.Second
+)
+
+// DefaultProtectedNamespaces are the namespaces destructive actions refuse to touch
+var DefaultProtectedNamespaces = []string{"openshift-*", "kube-*", "redhat-ods-*", "maas-api", "opendatahub"}
+
+// Settings holds the non-authentication options used by the handlers
+type Settings struct {
+	// ModelNamespace is the namespace offered by default for model actions
+	ModelNamespace string
+	// GatewayName and GatewayNamespace identify the MaaS gateway models attach to
+	GatewayName      string
+	GatewayNamespace string
+
+	// Timeout bounds every menu operation; 0 disables it
+	Timeout time.Duration
+
+	// ProtectedNamespaces are glob patterns, e.g. "openshift-*", of namespaces
+	// that cannot be deleted
+	ProtectedNamespaces []string
+
+	// RevokeOnExit revokes tokens the tool obtained itself when the menu exits.
+	// It is off by default, since revoking also empties the token cache; shared
+	// hosts turn it on.
+	RevokeOnExit bool
+	// ShowToken prints the bearer token after login (it is never shown otherwise)
+	ShowToken bool
+}
+
+// Config is the fully resolved configuration for one run
+type Config struct {
+	// ProfileName is the config file profile in use, or "" when none was selected
+	ProfileName string
+	// ConfigPath is the config file given by flag or environment ("" for the default)
+	ConfigPath string
+	Auth       *auth.Config
+	Settings   Settings
+
+	// args are the command-line arguments, kept so other profiles can be loaded with the same flags
+	args []string
+}
+
+// Load builds the configuration from, highest precedence first: command-line
+// flags, OCP_LISTER_* environment variables, the selected profile of the config
+// file, and built-in defaults. It returns the arguments left after the flags.
+func Load(args []string) (*Config, []string, error) {
+	return load(args, "")
+}
+
+// WithProfile loads another profile from the config file, applying the same
+// flags and environment variables on top of it
+func (c *Config) WithProfile(name string) (*Config, error) {
+	cfg, _, err := load(c.args, name)
+	return cfg, err
+}
+
+// WithKubeContext returns a copy that authenticates with the given kubeconfig context
+func (c *Config) WithKubeContext(context string) (*Config, error) {
+	authConfig := *c.Auth
+	authConfig.Method = auth.MethodKubeconfig
+	authConfig.KubeContext = context
+	authConfig.Server = ""
+	authConfig.ImpersonateUser = ""
+	authConfig.ImpersonateGroups = nil
+	if err := authConfig.Resolve(); err != nil {
+		return nil, err
+	}
+
+	cfg := *c
+	cfg.ProfileName = ""
+	cfg.Auth = &authConfig
+	return &cfg, nil
+}
+
+// load implements Load; a non-empty profileOverride takes precedence over --profile
+func load(args []string, profileOverride string) (*Config, []string, error) {
+	var flags Profile
+	var configPath, profileName string
+
+	fs := flag.NewFlagSet("ocp-lister", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&configPath, "config", "", "path to the config file (default ~/.config/ocp-lister/config.yaml)")
+	fs.StringVar(&profileName, "profile", "", "profile to use from the config file")
+	bindFlags(fs, &flags)
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if configPath == "" {
+		configPath = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if profileOverride != "" {
+		profileName = profileOverride
+	}
+	if profileName == "" {
+		profileName = os.Getenv(EnvPrefix + "PROFILE")
+	}
+
+	file, err := LoadFile(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileProfile, profileName, err := file.Select(profileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envProfile, err := profileFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Lowest precedence first; each layer only overrides the values it sets
+	revokeOnExit, showToken := false, false
+	merged := Profile{
+		ModelNamespace:   DefaultModelNamespace,
+		GatewayName:      DefaultGatewayName,
+		GatewayNamespace: DefaultGatewayNamespace,
+		RevokeOnExit:     &revokeOnExit,
+		ShowToken:        &showToken,
+
+		ProtectedNamespaces: DefaultProtectedNamespaces,
+	}
+	merged.merge(fileProfile)
+	merged.merge(envProfile)
+	merged.merge(flags)
+
+	for _, pattern := range merged.ProtectedNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid protected namespace pattern %q: %w", pattern, err)
+		}
+	}
+
+	timeout := DefaultTimeout
+	if merged.Timeout != "" {
+		timeout, err = time.ParseDuration(merged.Timeout)
+		if err != nil || timeout < 0 {
+			return nil, nil, fmt.Errorf("invalid timeout %q: use a duration such as 30s or 2m, or 0 to disable", merged.Timeout)
+		}
+	}
+
+	authConfig := merged.authConfig()
+	authConfig.Password = os.Getenv(EnvPrefix + "PASSWORD")
+	authConfig.Token = tokenEnv("TOKEN")
+	authConfig.OIDCClientSecret = os.Getenv(EnvPrefix + "OIDC_CLIENT_SECRET")
+	authConfig.PromptPassword = func() (string, error) {
+		return promptPassword(authConfig.Username, authConfig.Server)
+	}
+
+	if err := authConfig.Resolve(); err != nil {
+		return nil, nil, fmt.Errorf("%w (see --help for flags, %s* variables and the config file)", err, EnvPrefix)
+	}
+
+	return &Config{
+		ProfileName: profileName,
+		ConfigPath:  configPath,
+		Auth:        authConfig,
+		Settings: Settings{
+			ModelNamespace:   merged.ModelNamespace,
+			GatewayName:      merged.GatewayName,
+			GatewayNamespace: merged.GatewayNamespace,
+			Timeout:          timeout,
+			RevokeOnExit:     *merged.RevokeOnExit,
+			ShowToken:        *merged.ShowToken,
+
+			ProtectedNamespaces: merged.ProtectedNamespaces,
+		},
+		args: args,
+	}, fs.Args(), nil
+}
+
+// bindFlags registers a flag for every profile setting
+func bindFlags(fs *flag.FlagSet, p *Profile) {
+	fs.StringVar(&p.Server, "server", "", "OpenShift API server URL")
+	fs.Func("auth-method", "auto, kubeconfig, password, token, in-cluster or oidc", func(value string) error {
+		p.AuthMethod = auth.Method(strings.ToLower(value))
+		return nil
+	})
+	fs.StringVar(&p.Context, "context", "", "kubeconfig context to use (default the current context)")
+	fs.StringVar(&p.Username, "username", "", "username for the password method")
+	fs.StringVar(&p.TokenFile, "token-file", "", "file holding a bearer token")
+	fs.StringVar(&p.CAFile, "ca-file", "", "PEM CA bundle used to verify the servers")
+	fs.BoolFunc("insecure-skip-tls-verify", "disable TLS certificate verification (development only)", boolFlag(&p.InsecureSkipTLSVerify))
+	fs.StringVar(&p.ImpersonateUser, "as", "", "user to impersonate")
+	fs.Func("as-group", "group to impersonate (repeatable, requires --as)", func(value string) error {
+		p.ImpersonateGroups = append(p.ImpersonateGroups, value)
+		return nil
+	})
+	fs.StringVar(&p.OIDC.IssuerURL, "oidc-issuer-url", "", "OIDC issuer (Keycloak realm) URL")
+	fs.StringVar(&p.OIDC.ClientID, "oidc-client-id", "", "OIDC client ID")
+	fs.StringVar(&p.OIDC.Flow, "oidc-flow", "", "OIDC login flow: browser or device")
+	fs.StringVar(&p.OIDC.CAFile, "oidc-ca-file", "", "PEM CA bundle used to verify the OIDC issuer (default the system roots)")
+	fs.StringVar(&p.ModelNamespace, "model-namespace", "", "default namespace for model actions (default "+DefaultModelNamespace+")")
+	fs.StringVar(&p.GatewayName, "gateway-name", "", "MaaS gateway name (default "+DefaultGatewayName+")")
+	fs.StringVar(&p.GatewayNamespace, "gateway-namespace", "", "MaaS gateway namespace (default "+DefaultGatewayNamespace+")")
+	fs.StringVar(&p.Timeout, "timeout", "", "time limit for each operation, e.g. 2m; 0 disables it (default "+DefaultTimeout.String()+")")
+	fs.Func("protected-namespaces", "comma-separated namespaces, or patterns such as openshift-*, that cannot be deleted; empty protects none (default "+strings.Join(DefaultProtectedNamespaces, ",")+")", func(value string) error {
+		p.ProtectedNamespaces = splitList(value)
+		return nil
+	})
+	fs.BoolFunc("revoke-on-exit", "revoke the token obtained at login when exiting the menu, e.g. on shared hosts", boolFlag(&p.RevokeOnExit))
+	fs.BoolFunc("show-token", "print the bearer token after login", boolFlag(&p.ShowToken))
+}
+
+// boolFlag returns a flag setter for an optional boolean, so unset flags do not override other layers
+func boolFlag(dst **bool) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*dst = &parsed
+		return nil
+	}
+}
+
+// tokenEnv reads the OCP_LISTER_ variable name, falling back to the plain
+// TOKEN or TOKEN_FILE that Jobs and CronJobs are often given
+func tokenEnv(name string) string {
+	if value := os.Getenv(EnvPrefix + name); value != "" {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// profileFromEnv reads the OCP_LISTER_* environment variables
+func profileFromEnv() (Profile, error) {
+	p := Profile{
+		Server:          os.Getenv(EnvPrefix + "SERVER"),
+		AuthMethod:      auth.Method(strings.ToLower(os.Getenv(EnvPrefix + "AUTH_METHOD"))),
+		Context:         os.Getenv(EnvPrefix + "CONTEXT"),
+		Username:        os.Getenv(EnvPrefix + "USERNAME"),
+		TokenFile:       tokenEnv("TOKEN_FILE"),
+		CAFile:          os.Getenv(EnvPrefix + "CA_FILE"),
+		ImpersonateUser: os.Getenv(EnvPrefix + "IMPERSONATE_USER"),
+		OIDC: OIDCProfile{
+			IssuerURL: os.Getenv(EnvPrefix + "OIDC_ISSUER_URL"),
+			ClientID:  os.Getenv(EnvPrefix + "OIDC_CLIENT_ID"),
+			Flow:      strings.ToLower(os.Getenv(EnvPrefix + "OIDC_FLOW")),
+			CAFile:    os.Getenv(EnvPrefix + "OIDC_CA_FILE"),
+		},
+		ModelNamespace:   os.Getenv(EnvPrefix + "MODEL_NAMESPACE"),
+		GatewayName:      os.Getenv(EnvPrefix + "GATEWAY_NAME"),
+		GatewayNamespace: os.Getenv(EnvPrefix + "GATEWAY_NAMESPACE"),
+		Timeout:          os.Getenv(EnvPrefix + "TIMEOUT"),
+	}
+
+	p.ImpersonateGroups = splitList(os.Getenv(EnvPrefix + "IMPERSONATE_GROUPS"))
+	p.OIDC.Scopes = splitList(os.Getenv(EnvPrefix + "OIDC_SCOPES"))
+	if value, ok := os.LookupEnv(EnvPrefix + "PROTECTED_NAMESPACES"); ok {
+		p.ProtectedNamespaces = splitList(value)
+	}
+
+	for name, dst := range map[string]**bool{
+		"INSECURE_SKIP_TLS_VERIFY": &p.InsecureSkipTLSVerify,
+		"REVOKE_ON_EXIT":           &p.RevokeOnExit,
+		"SHOW_TOKEN":               &p.ShowToken,
+	} {
+		if value := os.Getenv(EnvPrefix + name); value != "" {
+			if err := boolFlag(dst)(value); err != nil {
+				return p, fmt.Errorf("invalid %s%s value: %q", EnvPrefix, name, value)
+			}
+		}
+	}
+
+	if value := os.Getenv(EnvPrefix + "OIDC_CALLBACK_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 0 || port > 65535 {
+			return p, fmt.Errorf("invalid %sOIDC_CALLBACK_PORT value: %q", EnvPrefix, value)
+		}
+		p.OIDC.CallbackPort = port
+	}
+
+	return p, nil
+}
+
+// splitList splits a comma or space separated value. An empty value gives an
+// empty, not nil, list, so it can clear a list set by a lower layer.
+func splitList(value string) []string {
+	return append([]string{}, strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})...)
+}
+
+// printUsage prints the flags and the matching environment variables
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "Usage: ocp-lister [flags] [command]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Without a command the interactive menu starts. Run 'ocp-lister help' to list the commands.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	fs.SetOutput(out)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Every flag can also be set as an environment variable with the %s prefix,\n", EnvPrefix)
+	fmt.Fprintf(out, "e.g. --model-namespace becomes %sMODEL_NAMESPACE. Secrets are only read from\n", EnvPrefix)
+	fmt.Fprintf(out, "the environment: %sPASSWORD, %sTOKEN and %sOIDC_CLIENT_SECRET.\n", EnvPrefix, EnvPrefix, EnvPrefix)
+	fmt.Fprintf(out, "Plain TOKEN and TOKEN_FILE are used when %sTOKEN and %sTOKEN_FILE are unset.\n", EnvPrefix, EnvPrefix)
+	fmt.Fprintln(out, "Precedence: flags, then environment, then the config file profile, then defaults.")
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+)
+
+// isolate clears the OCP_LISTER_* environment and the default config file, and
+// supplies a token so the configuration resolves to the token method
+func isolate(t *testing.T) {
+	t.Helper()
+	for _, entry := range os.Environ() {
+		if name, _, _ := strings.Cut(entry, "="); strings.HasPrefix(name, EnvPrefix) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+	t.Setenv("TOKEN", "")
+	t.Setenv("TOKEN_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"TOKEN", "test-token")
+}
+
+// writeConfig writes a config file whose current profile "test" holds the settings
+func writeConfig(t *testing.T, settings map[string]string) string {
+	t.Helper()
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("currentProfile: test\nprofiles:\n  test:\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "    %s: %q\n", key, settings[key])
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	settings := []struct {
+		flag    string
+		fileKey string
+		env     string
+		// def is the built-in default; "" when the setting has none
+		def                            string
+		fileValue, envValue, flagValue string
+		get                            func(*Config) string
+	}{
+		{
+			flag: "model-namespace", fileKey: "modelNamespace", env: "MODEL_NAMESPACE", def: DefaultModelNamespace,
+			fileValue: "file-models", envValue: "env-models", flagValue: "flag-models",
+			get: func(c *Config) string { return c.Settings.ModelNamespace },
+		},
+		{
+			flag: "gateway-name", fileKey: "gatewayName", env: "GATEWAY_NAME", def: DefaultGatewayName,
+			fileValue: "file-gateway", envValue: "env-gateway", flagValue: "flag-gateway",
+			get: func(c *Config) string { return c.Settings.GatewayName },
+		},
+		{
+			flag: "gateway-namespace", fileKey: "gatewayNamespace", env: "GATEWAY_NAMESPACE", def: DefaultGatewayNamespace,
+			fileValue: "file-ingress", envValue: "env-ingress", flagValue: "flag-ingress",
+			get: func(c *Config) string { return c.Settings.GatewayNamespace },
+		},
+		{
+			flag: "timeout", fileKey: "timeout", env: "TIMEOUT", def: DefaultTimeout.String(),
+			fileValue: "1m0s", envValue: "2m0s", flagValue: "3m0s",
+			get: func(c *Config) string { return c.Settings.Timeout.String() },
+		},
+		{
+			flag: "server", fileKey: "server", env: "SERVER",
+			fileValue: "https://file.example.com:6443", envValue: "https://env.example.com:6443", flagValue: "https://flag.example.com:6443",
+			get: func(c *Config) string { return c.Auth.Server },
+		},
+		{
+			flag: "ca-file", fileKey: "caFile", env: "CA_FILE",
+			fileValue: "/file/ca.pem", envValue: "/env/ca.pem", flagValue: "/flag/ca.pem",
+			get: func(c *Config) string { return c.Auth.CAFile },
+		},
+	}
+
+	for _, setting := range settings {
+		for level, layer := range []string{"default", "profile", "env", "flag"} {
+			if level == 0 && setting.def == "" {
+				continue
+			}
+			t.Run(setting.flag+"/"+layer, func(t *testing.T) {
+				isolate(t)
+
+				profile := map[string]string{"server": "https://profile.example.com:6443"}
+				var args []string
+				if level >= 1 {
+					profile[setting.fileKey] = setting.fileValue
+				}
+				if level >= 2 {
+					t.Setenv(EnvPrefix+setting.env, setting.envValue)
+				}
+				if level >= 3 {
+					args = append(args, "--"+setting.flag+"="+setting.flagValue)
+				}
+				args = append(args, "--config", writeConfig(t, profile), "projects", "list")
+
+				cfg, rest, err := Load(args)
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				want := []string{setting.def, setting.fileValue, setting.envValue, setting.flagValue}[level]
+				if got := setting.get(cfg); got != want {
+					t.Errorf("%s = %q, want %q", setting.flag, got, want)
+				}
+				if !reflect.DeepEqual(rest, []string{"projects", "list"}) {
+					t.Errorf("Load() rest = %q, want the subcommand", rest)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadBoolPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		env     string
+		flag    string
+		want    bool
+	}{
+		{name: "default", want: false},
+		{name: "profile", profile: "false", want: false},
+		{name: "env over profile", profile: "false", env: "true", want: true},
+		{name: "flag over env", profile: "true", env: "true", flag: "--revoke-on-exit=false", want: false},
+		{name: "bare flag", env: "false", flag: "--revoke-on-exit", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+
+			// Quoted strings do not unmarshal into *bool, so write the file by hand
+			content := "currentProfile: test\nprofiles:\n  test:\n    server: https://profile.example.com:6443\n"
+			if tt.profile != "" {
+				content += "    revokeOnExit: " + tt.profile + "\n"
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.env != "" {
+				t.Setenv(EnvPrefix+"REVOKE_ON_EXIT", tt.env)
+			}
+			args := []string{"--config", path}
+			if tt.flag != "" {
+				args = append(args, tt.flag)
+			}
+
+			cfg, _, err := Load(args)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Settings.RevokeOnExit != tt.want {
+				t.Errorf("RevokeOnExit = %v, want %v", cfg.Settings.RevokeOnExit, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileSelection(t *testing.T) {
+	content := `currentProfile: dev
+profiles:
+  dev:
+    server: https://dev.example.com:6443
+  prod:
+    server: https://prod.example.com:6443
+  lab:
+    server: https://lab.example.com:6443
+`
+
+	tests := []struct {
+		name        string
+		env         string
+		args        []string
+		wantProfile string
+		wantErr     bool
+	}{
+		{name: "current profile", wantProfile: "dev"},
+		{name: "env", env: "prod", wantProfile: "prod"},
+		{name: "flag over env", env: "prod", args: []string{"--profile", "lab"}, wantProfile: "lab"},
+		{name: "unknown", args: []string{"--profile", "missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.env != "" {
+				t.Setenv(EnvPrefix+"PROFILE", tt.env)
+			}
+
+			cfg, _, err := Load(append([]string{"--config", path}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.ProfileName != tt.wantProfile {
+				t.Errorf("ProfileName = %q, want %q", cfg.ProfileName, tt.wantProfile)
+			}
+			if want := "https://" + tt.wantProfile + ".example.com:6443"; cfg.Auth.Server != want {
+				t.Errorf("Server = %q, want %q", cfg.Auth.Server, want)
+			}
+			if cfg.Auth.Method != auth.MethodToken {
+				t.Errorf("Method = %q, want %q", cfg.Auth.Method, auth.MethodToken)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{name: "bad timeout", args: []string{"--timeout", "soon"}},
+		{name: "negative timeout", env: map[string]string{"TIMEOUT": "-1s"}},
+		{name: "missing config file", args: []string{"--config", "/nonexistent/config.yaml"}},
+		{name: "unknown flag", args: []string{"--bogus"}},
+		{name: "groups without user", args: []string{"--as-group", "admins"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			t.Setenv(EnvPrefix+"SERVER", "https://env.example.com:6443")
+			for name, value := range tt.env {
+				t.Setenv(EnvPrefix+name, value)
+			}
+
+			if _, _, err := Load(tt.args); err == nil {
+				t.Errorf("Load(%q) succeeded, want an error", tt.args)
+			}
+		})
+	}
+}
+
+func TestLoadTokenFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		wantToken     string
+		wantTokenFile string
+	}{
+		{name: "prefixed", env: map[string]string{EnvPrefix + "TOKEN": "prefixed", "TOKEN": "plain"}, wantToken: "prefixed"},
+		{name: "plain token", env: map[string]string{"TOKEN": "plain"}, wantToken: "plain"},
+		{name: "plain token file", env: map[string]string{"TOKEN_FILE": "/plain/token"}, wantTokenFile: "/plain/token"},
+		{
+			name:          "prefixed token file",
+			env:           map[string]string{EnvPrefix + "TOKEN_FILE": "/prefixed/token", "TOKEN_FILE": "/plain/token"},
+			wantTokenFile: "/prefixed/token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			t.Setenv(EnvPrefix+"TOKEN", "")
+			t.Setenv(EnvPrefix+"SERVER", "https://env.example.com:6443")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, _, err := Load(nil)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Auth.Token != tt.wantToken || cfg.Auth.TokenFile != tt.wantTokenFile {
+				t.Errorf("Token, TokenFile = %q, %q, want %q, %q", cfg.Auth.Token, cfg.Auth.TokenFile, tt.wantToken, tt.wantTokenFile)
+			}
+			if cfg.Auth.Method != auth.MethodToken {
+				t.Errorf("Method = %q, want %q", cfg.Auth.Method, auth.MethodToken)
+			}
+		})
+	}
+}
+
+func TestLoadProtectedNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     *string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default", want: DefaultProtectedNamespaces},
+		{name: "env", env: ptr("team-*, lab"), want: []string{"team-*", "lab"}},
+		{name: "empty env protects none", env: ptr(""), want: nil},
+		{name: "flag over env", env: ptr("lab"), args: []string{"--protected-namespaces", "prod-*"}, want: []string{"prod-*"}},
+		{name: "bad pattern", args: []string{"--protected-namespaces", "["}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			t.Setenv(EnvPrefix+"SERVER", "https://env.example.com:6443")
+			if tt.env != nil {
+				t.Setenv(EnvPrefix+"PROTECTED_NAMESPACES", *tt.env)
+			}
+
+			cfg, _, err := Load(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(cfg.Settings.ProtectedNamespaces) != len(tt.want) ||
+				(len(tt.want) > 0 && !reflect.DeepEqual(cfg.Settings.ProtectedNamespaces, tt.want)) {
+				t.Errorf("ProtectedNamespaces = %q, want %q", cfg.Settings.ProtectedNamespaces, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+	"sigs.k8s.io/yaml"
+)
+
+// File is the on-disk config file holding named profiles
+type File struct {
+	// CurrentProfile is used when no profile is selected by flag or environment
+	CurrentProfile string             `json:"currentProfile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds the settings for one cluster. Secrets are deliberately not stored here.
+type Profile struct {
+	Server                string      `json:"server,omitempty"`
+	AuthMethod            auth.Method `json:"authMethod,omitempty"`
+	Context               string      `json:"context,omitempty"`
+	Username              string      `json:"username,omitempty"`
+	TokenFile             string      `json:"tokenFile,omitempty"`
+	CAFile                string      `json:"caFile,omitempty"`
+	InsecureSkipTLSVerify *bool       `json:"insecureSkipTLSVerify,omitempty"`
+	ImpersonateUser       string      `json:"impersonateUser,omitempty"`
+	ImpersonateGroups     []string    `json:"impersonateGroups,omitempty"`
+	OIDC                  OIDCProfile `json:"oidc,omitempty"`
+	ModelNamespace        string      `json:"modelNamespace,omitempty"`
+	GatewayName           string      `json:"gatewayName,omitempty"`
+	GatewayNamespace      string      `json:"gatewayNamespace,omitempty"`
+	RevokeOnExit          *bool       `json:"revokeOnExit,omitempty"`
+	Timeout               string      `json:"timeout,omitempty"`
+	// ProtectedNamespaces replaces the default list when set; an empty list protects none
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+	// ShowToken is only settable per run, by flag or environment
+	ShowToken *bool `json:"-"`
+}
+
+// OIDCProfile holds the OIDC provider settings of a profile
+type OIDCProfile struct {
+	IssuerURL    string   `json:"issuerURL,omitempty"`
+	ClientID     string   `json:"clientID,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Flow         string   `json:"flow,omitempty"`
+	CallbackPort int      `json:"callbackPort,omitempty"`
+	CAFile       string   `json:"caFile,omitempty"`
+}
+
+// DefaultPath returns ~/.config/ocp-lister/config.yaml (honouring XDG_CONFIG_HOME)
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ocp-lister", "config.yaml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "ocp-lister", "config.yaml"), nil
+}
+
+// LoadFile reads the config file. A missing file at the default path is not an
+// error, but a missing file that was asked for explicitly is.
+func LoadFile(path string) (*File, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Select returns the named profile, falling back to CurrentProfile.
+// It returns an empty profile when no profile is selected.
+func (f *File) Select(name string) (Profile, string, error) {
+	if name == "" {
+		name = f.CurrentProfile
+	}
+	if name == "" {
+		return Profile{}, "", nil
+	}
+
+	profile, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, "", fmt.Errorf("profile %q not found in config file (available: %s)", name, strings.Join(f.ProfileNames(), ", "))
+	}
+
+	return profile, name, nil
+}
+
+// ProfileNames returns the profile names in sorted order
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge overrides the receiver with every value set in src
+func (p *Profile) merge(src Profile) {
+	setString(&p.Server, src.Server)
+	if src.AuthMethod != "" {
+		p.AuthMethod = src.AuthMethod
+	}
+	setString(&p.Context, src.Context)
+	setString(&p.Username, src.Username)
+	setString(&p.TokenFile, expandHome(src.TokenFile))
+	setString(&p.CAFile, expandHome(src.CAFile))
+	if src.InsecureSkipTLSVerify != nil {
+		p.InsecureSkipTLSVerify = src.InsecureSkipTLSVerify
+	}
+	setString(&p.ImpersonateUser, src.ImpersonateUser)
+	if len(src.ImpersonateGroups) > 0 {
+		p.ImpersonateGroups = src.ImpersonateGroups
+	}
+	setString(&p.OIDC.IssuerURL, src.OIDC.IssuerURL)
+	setString(&p.OIDC.ClientID, src.OIDC.ClientID)
+	if len(src.OIDC.Scopes) > 0 {
+		p.OIDC.Scopes = src.OIDC.Scopes
+	}
+	setString(&p.OIDC.Flow, src.OIDC.Flow)
+	if src.OIDC.CallbackPort != 0 {
+		p.OIDC.CallbackPort = src.OIDC.CallbackPort
+	}
+	setString(&p.OIDC.CAFile, expandHome(src.OIDC.CAFile))
+	setString(&p.ModelNamespace, src.ModelNamespace)
+	setString(&p.GatewayName, src.GatewayName)
+	setString(&p.GatewayNamespace, src.GatewayNamespace)
+	setString(&p.Timeout, src.Timeout)
+	if src.RevokeOnExit != nil {
+		p.RevokeOnExit = src.RevokeOnExit
+	}
+	if src.ShowToken != nil {
+		p.ShowToken = src.ShowToken
+	}
+	if src.ProtectedNamespaces != nil {
+		p.ProtectedNamespaces = src.ProtectedNamespaces
+	}
+}
+
+// authConfig converts the profile into an auth config (without secrets)
+func (p *Profile) authConfig() *auth.Config {
+	return &auth.Config{
+		Method:                p.AuthMethod,
+		KubeContext:           p.Context,
+		Username:              p.Username,
+		Server:                strings.TrimSuffix(p.Server, "/"),
+		TokenFile:             p.TokenFile,
+		CAFile:                p.CAFile,
+		InsecureSkipTLSVerify: p.InsecureSkipTLSVerify != nil && *p.InsecureSkipTLSVerify,
+		ImpersonateUser:       p.ImpersonateUser,
+		ImpersonateGroups:     p.ImpersonateGroups,
+		OIDCIssuerURL:         strings.TrimSuffix(p.OIDC.IssuerURL, "/"),
+		OIDCClientID:          p.OIDC.ClientID,
+		OIDCScopes:            p.OIDC.Scopes,
+		OIDCFlow:              p.OIDC.Flow,
+		OIDCCallbackPort:      p.OIDC.CallbackPort,
+		OIDCCAFile:            p.OIDC.CAFile,
+	}
+}
+
+// setString overrides dst when value is set
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// expandHome expands a leading ~/ in a path
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
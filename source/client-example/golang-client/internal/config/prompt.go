@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// promptPassword reads the password from the terminal without echoing it
+func promptPassword(username, server string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("password is required: set %sPASSWORD or run from a terminal to be prompted", EnvPrefix)
+	}
+
+	fmt.Fprintf(os.Stderr, "Password for %s on %s: ", username, server)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	if len(password) == 0 {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Target kinds
+const (
+	TargetProfile = "profile"
+	TargetContext = "context"
+)
+
+// Target is a cluster the session can switch to
+type Target struct {
+	Kind   string
+	Name   string
+	Server string
+}
+
+// String describes the target for display in menus
+func (t Target) String() string {
+	if t.Server == "" {
+		return fmt.Sprintf("%s (%s)", t.Name, t.Kind)
+	}
+	return fmt.Sprintf("%s (%s, %s)", t.Name, t.Kind, t.Server)
+}
+
+// Targets lists the config file profiles followed by the kubeconfig contexts
+func (c *Config) Targets() ([]Target, error) {
+	var targets []Target
+
+	file, err := LoadFile(c.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range file.ProfileNames() {
+		targets = append(targets, Target{Kind: TargetProfile, Name: name, Server: file.Profiles[name].Server})
+	}
+
+	kubeconfig, err := clientcmd.LoadFromFile(auth.KubeconfigPath())
+	if err == nil {
+		names := make([]string, 0, len(kubeconfig.Contexts))
+		for name := range kubeconfig.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			target := Target{Kind: TargetContext, Name: name}
+			if cluster, ok := kubeconfig.Clusters[kubeconfig.Contexts[name].Cluster]; ok {
+				target.Server = cluster.Server
+			}
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, nil
+}
+
+// ForTarget loads the configuration for a profile or kubeconfig context
+func (c *Config) ForTarget(target Target) (*Config, error) {
+	if target.Kind == TargetContext {
+		return c.WithKubeContext(target.Name)
+	}
+	return c.WithProfile(target.Name)
+}
@@ -0,0 +1,65 @@
+package menu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Input reads menu choices and answers. Every menu and prompt shares one
+// buffered reader, so a script piped to stdin is consumed one line per prompt.
+type Input struct {
+	reader *bufio.Reader
+	out    io.Writer
+	// echo repeats each line read after its prompt, so the output of a
+	// replayed script reads like an interactive session
+	echo bool
+}
+
+// NewInput creates an Input that reads from r and writes menus and prompts to w
+func NewInput(r io.Reader, w io.Writer, echo bool) *Input {
+	return &Input{reader: bufio.NewReader(r), out: w, echo: echo}
+}
+
+// input is used by all menus and prompts
+var input = NewInput(os.Stdin, os.Stdout, !term.IsTerminal(int(os.Stdin.Fd())))
+
+// SetInput replaces the input used by all menus and prompts, e.g. to replay a
+// recorded script or to drive the menus from a test
+func SetInput(in *Input) {
+	input = in
+}
+
+// ReadLine prints the prompt and reads one line without the trailing newline.
+// It returns io.EOF once the input is exhausted.
+func (in *Input) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(in.out, prompt)
+
+	line, err := in.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		// A last line without a newline still counts; EOF is returned on the next read
+		err = nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			fmt.Fprintln(in.out)
+			return "", io.EOF
+		}
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if in.echo {
+		fmt.Fprintln(in.out, line)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// ReadLine prints the prompt and reads one line from the shared input
+func ReadLine(prompt string) (string, error) {
+	return input.ReadLine(prompt)
+}
@@ -0,0 +1,106 @@
+package menu
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useInput replaces the shared input for the rest of the test and returns
+// what the menus print
+func useInput(t *testing.T, script string) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	previous := input
+	SetInput(NewInput(strings.NewReader(script), &out, false))
+	t.Cleanup(func() { SetInput(previous) })
+	return &out
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		echo    bool
+		want    []string
+		wantOut string
+	}{
+		{name: "lines", script: "one\ntwo\n", want: []string{"one", "two"}, wantOut: "> > > \n"},
+		{name: "last line without newline", script: "one\ntwo", want: []string{"one", "two"}, wantOut: "> > > \n"},
+		{name: "trims spaces and CRLF", script: "  one  \r\n", want: []string{"one"}, wantOut: "> > \n"},
+		{name: "empty line", script: "\n", want: []string{""}, wantOut: "> > \n"},
+		{name: "empty input", script: "", want: nil, wantOut: "> \n"},
+		{name: "echo", script: "one\ntwo", echo: true, want: []string{"one", "two"}, wantOut: "> one\n> two\n> \n"},
+		{name: "echo keeps spaces", script: " one \n", echo: true, want: []string{"one"}, wantOut: ">  one \n> \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			in := NewInput(strings.NewReader(tt.script), &out, tt.echo)
+
+			var got []string
+			for {
+				line, err := in.ReadLine("> ")
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ReadLine() error = %v", err)
+				}
+				got = append(got, line)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLine() lines = %q, want %q", got, tt.want)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("ReadLine() printed %q, want %q", out.String(), tt.wantOut)
+			}
+
+			// EOF is sticky
+			if _, err := in.ReadLine("> "); err != io.EOF {
+				t.Errorf("ReadLine() after EOF error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReadLineError(t *testing.T) {
+	in := NewInput(errReader{}, io.Discard, false)
+	_, err := in.ReadLine("> ")
+	if err == nil || err == io.EOF {
+		t.Fatalf("ReadLine() error = %v, want a read error", err)
+	}
+}
+
+func TestGetConfirmation(t *testing.T) {
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{script: "yes\n", want: true},
+		{script: "y\n", want: true},
+		{script: "YES\n", want: true},
+		{script: "no\n"},
+		{script: "yep\n"},
+		{script: "\n"},
+		{script: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.script), func(t *testing.T) {
+			useInput(t, tt.script)
+			if got := GetConfirmation("Continue?"); got != tt.want {
+				t.Errorf("GetConfirmation(%q) = %v, want %v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
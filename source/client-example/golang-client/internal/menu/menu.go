@@ -1,84 +1,212 @@
 package menu
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
+
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// Menu represents a menu with options
+// headerFunc returns the status line shown under every menu title
+var headerFunc func() string
+
+// SetHeader sets a function whose result is shown under every menu title,
+// such as the current cluster and identity
+func SetHeader(fn func() string) {
+	headerFunc = fn
+}
+
+// printHeader prints the status line, if any
+func printHeader() {
+	if headerFunc == nil {
+		return
+	}
+	if header := headerFunc(); header != "" {
+		fmt.Fprintln(input.out, header)
+	}
+}
+
+// State is shared by the actions of a menu and its submenus
+type State struct {
+	// Session is the current session; actions such as impersonation replace it
+	Session *session.Session
+}
+
+// Item is one option of a menu. Choosing it runs Action, or opens Submenu.
+type Item struct {
+	Key   string
+	Title string
+	// Order places the item in its menu; lower comes first, then by key
+	Order   int
+	Action  func(state *State)
+	Submenu *Menu
+	// Permission, if set, is checked when the menu opens, for every namespace
+	// and object. Items the current identity may not use everywhere are greyed
+	// out but can still be chosen: the action checks the namespace and object
+	// picked, which a tenant may well be allowed.
+	Permission func() access.Permission
+}
+
+// Menu is a titled list of items shown in a fixed order
 type Menu struct {
-	Title   string
-	Options map[string]string
+	Title string
+	Items []Item
 }
 
-// NewMenu creates a new menu
-func NewMenu(title string) *Menu {
-	return &Menu{
-		Title:   title,
-		Options: make(map[string]string),
+// NewMenu creates a menu with the given items
+func NewMenu(title string, items ...Item) *Menu {
+	m := &Menu{Title: title}
+	for _, item := range items {
+		m.Add(item)
 	}
+	return m
 }
 
-// AddOption adds an option to the menu
-func (m *Menu) AddOption(key, description string) {
-	m.Options[key] = description
+// Add adds an item, keeping the items ordered. Keys must be unique within a
+// menu, and submenus cannot use B, which goes back.
+func (m *Menu) Add(item Item) {
+	item.Key = strings.ToUpper(item.Key)
+	if m.item(item.Key) != nil {
+		panic(fmt.Sprintf("menu %q: duplicate key %s for %q", m.Title, item.Key, item.Title))
+	}
+
+	m.Items = append(m.Items, item)
+	sort.SliceStable(m.Items, func(i, j int) bool {
+		if m.Items[i].Order != m.Items[j].Order {
+			return m.Items[i].Order < m.Items[j].Order
+		}
+		return m.Items[i].Key < m.Items[j].Key
+	})
+}
+
+// item returns the item with the given key, or nil
+func (m *Menu) item(key string) *Item {
+	for i := range m.Items {
+		if m.Items[i].Key == key {
+			return &m.Items[i]
+		}
+	}
+	return nil
+}
+
+// Run shows the menu and runs the chosen items until the user goes back. At
+// the end of the input the menu is left as if B was chosen, or, for the top
+// menu, by running its X item, so a piped script that runs out exits cleanly.
+func (m *Menu) Run(state *State) {
+	m.run(state, nil)
 }
 
-// Display shows the menu and returns the selected option
-func (m *Menu) Display() (string, error) {
-	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Println(m.Title)
-	fmt.Println(strings.Repeat("=", 50))
+// run shows the menu below the given parent menus
+func (m *Menu) run(state *State, parents []string) {
+	path := append(append([]string(nil), parents...), m.Title)
+	denied := m.denied(state.Session)
+
+	for {
+		choice, err := m.display(path, denied)
+		if err == io.EOF {
+			if exit := m.item("X"); exit != nil && len(parents) == 0 && exit.Action != nil {
+				exit.Action(state)
+			}
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(input.out, "Error: %v\n", err)
+			continue
+		}
+		if choice == "B" && len(parents) > 0 {
+			return
+		}
 
-	// Sort and display options
-	keys := []string{}
-	for key := range m.Options {
-		keys = append(keys, key)
+		item := m.item(choice)
+		if item.Submenu != nil {
+			item.Submenu.run(state, path)
+		} else if item.Action != nil {
+			item.Action(state)
+		}
 	}
+}
 
-	// Display in order (a, b, c, etc. then X)
-	for _, key := range keys {
-		if key != "X" && key != "x" {
-			fmt.Printf("%s. %s\n", strings.ToUpper(key), m.Options[key])
+// denied checks the permissions of the menu's items and returns the ones
+// that are not allowed everywhere, with the permission they lack. The checks run as an
+// operation, so Ctrl-C skips them instead of exiting.
+func (m *Menu) denied(sess *session.Session) map[string]string {
+	permissions := make(map[string]access.Permission)
+	for _, item := range m.Items {
+		if item.Permission != nil {
+			permissions[item.Key] = item.Permission()
 		}
 	}
+	if len(permissions) == 0 || sess == nil {
+		return nil
+	}
+
+	var denied map[string]string
+	_ = sess.Run(func(ctx context.Context) error {
+		denied = access.Denied(ctx, sess, permissions)
+		return nil
+	})
+	return denied
+}
 
-	// Always show exit option last
-	if exitDesc, exists := m.Options["X"]; exists {
-		fmt.Printf("X. %s\n", exitDesc)
-	} else if exitDesc, exists := m.Options["x"]; exists {
-		fmt.Printf("X. %s\n", exitDesc)
+// display shows the menu with a breadcrumb title and returns the chosen key.
+// It returns io.EOF when the input is exhausted.
+func (m *Menu) display(path []string, denied map[string]string) (string, error) {
+	out := input.out
+	rule := strings.Repeat("=", 50)
+	if len(path) > 1 {
+		rule = strings.Repeat("-", 50)
 	}
 
-	fmt.Println(strings.Repeat("=", 50))
-	fmt.Print("Select an option: ")
+	fmt.Fprintln(out, "\n"+rule)
+	fmt.Fprintln(out, strings.Join(path, " > "))
+	printHeader()
+	fmt.Fprintln(out, rule)
+	for _, item := range m.Items {
+		if _, isDenied := denied[item.Key]; isDenied {
+			fmt.Fprintf(out, "\033[2m%s. %s (restricted)\033[0m\n", item.Key, item.Title)
+			continue
+		}
+		fmt.Fprintf(out, "%s. %s\n", item.Key, item.Title)
+	}
+	if len(path) > 1 {
+		fmt.Fprintf(out, "B. Back to %s\n", path[len(path)-2])
+	}
+	fmt.Fprintln(out, rule)
 
-	reader := bufio.NewReader(os.Stdin)
-	choice, err := reader.ReadString('\n')
+	choice, err := input.ReadLine("Select an option: ")
 	if err != nil {
-		return "", fmt.Errorf("failed to read input: %w", err)
+		return "", err
 	}
+	choice = strings.ToUpper(choice)
 
-	choice = strings.TrimSpace(strings.ToUpper(choice))
-
-	// Validate choice
-	if _, exists := m.Options[choice]; !exists {
+	if choice == "B" && len(path) > 1 {
+		return choice, nil
+	}
+	if m.item(choice) == nil {
 		return "", fmt.Errorf("invalid option: %s", choice)
 	}
+	if permission, isDenied := denied[choice]; isDenied {
+		// Only a check for the chosen namespace and object can refuse the action
+		fmt.Fprintf(out, "Note: you cannot %s everywhere; checking the object you choose.\n", permission)
+	}
 
 	return choice, nil
 }
 
-// DisplayAndGetChoice displays the menu and returns the choice, handling errors
-func (m *Menu) DisplayAndGetChoice() string {
-	for {
-		choice, err := m.Display()
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			continue
-		}
-		return choice
-	}
+// mainMenu holds the items registered by object packages and the application
+var mainMenu = NewMenu("OpenShift Kubernetes Object Manager")
+
+// Register adds an item to the main menu. Object packages call it from init,
+// so adding a resource type only needs its package imported.
+func Register(item Item) {
+	mainMenu.Add(item)
+}
+
+// Main returns the main menu with every registered item
+func Main() *Menu {
+	return mainMenu
 }
@@ -0,0 +1,45 @@
+package menu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayRestrictedItem(t *testing.T) {
+	m := NewMenu("Models",
+		Item{Key: "1", Title: "Deploy"},
+		Item{Key: "2", Title: "List"},
+	)
+	denied := map[string]string{"1": "create llminferenceservices.serving.kserve.io"}
+
+	tests := []struct {
+		name     string
+		script   string
+		want     string
+		wantErr  bool
+		wantNote bool
+	}{
+		{name: "restricted item can be chosen", script: "1\n", want: "1", wantNote: true},
+		{name: "allowed item", script: "2\n", want: "2"},
+		{name: "unknown item", script: "9\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := useInput(t, tt.script)
+			got, err := m.display([]string{"Models"}, denied)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("display() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("display() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "1. Deploy (restricted)") {
+				t.Errorf("restricted item is not greyed out:\n%s", out)
+			}
+			if hasNote := strings.Contains(out.String(), "checking the object you choose"); hasNote != tt.wantNote {
+				t.Errorf("note shown = %v, want %v:\n%s", hasNote, tt.wantNote, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package menu
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxPicks is the number of names the picker shows at once; more are narrowed by filtering
+const maxPicks = 30
+
+// PickName prompts for the name of an existing object of the given kind.
+// Pressing Enter, or typing a name that does not exist, shows a numbered list
+// from the cluster that can be narrowed by typing part of a name. When the
+// names cannot be listed, e.g. without list permission, the error is shown and
+// the typed name is used unchecked, saying so.
+// It returns "" when cancelled or at the end of the input.
+func PickName(prompt, kind string, list func() ([]string, error)) string {
+	typed, err := input.ReadLine(prompt + " (or press Enter to pick from a list): ")
+	if err != nil {
+		return ""
+	}
+
+	names, err := list()
+	if err != nil {
+		fmt.Fprintf(input.out, "⚠️  Could not list %ss: %v\n", kind, err)
+		if typed == "" {
+			if typed, err = input.ReadLine(fmt.Sprintf("Type the %s name instead: ", kind)); err != nil {
+				return ""
+			}
+		}
+		if typed != "" {
+			fmt.Fprintf(input.out, "   Using '%s' as typed; it could not be checked against the list.\n", typed)
+		}
+		return typed
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == typed {
+			return typed
+		}
+	}
+	if typed != "" {
+		fmt.Fprintf(input.out, "No %s named '%s'.\n", kind, typed)
+	}
+
+	return pick(kind, names, typed)
+}
+
+// pick shows the names containing filter and reads a number or a new filter
+func pick(kind string, names []string, filter string) string {
+	for {
+		matches := matching(names, filter)
+		if len(matches) == 0 {
+			if filter == "" {
+				fmt.Fprintf(input.out, "No %ss found.\n", kind)
+				return ""
+			}
+			fmt.Fprintf(input.out, "No %ss match '%s'.\n", kind, filter)
+		} else {
+			shown := matches
+			if len(shown) > maxPicks {
+				shown = shown[:maxPicks]
+			}
+			for i, name := range shown {
+				fmt.Fprintf(input.out, "%3d. %s\n", i+1, name)
+			}
+			if len(matches) > len(shown) {
+				fmt.Fprintf(input.out, "     ... and %d more; type part of a name to narrow the list\n", len(matches)-len(shown))
+			}
+
+			// A number picks from the list shown
+			answer, err := input.ReadLine("Select a number, type to filter, or press Enter to cancel: ")
+			if err != nil || answer == "" {
+				return ""
+			}
+			if n, err := strconv.Atoi(answer); err == nil {
+				if n >= 1 && n <= len(shown) {
+					return shown[n-1]
+				}
+				fmt.Fprintf(input.out, "Invalid selection: %d\n", n)
+				continue
+			}
+			filter = answer
+			continue
+		}
+
+		answer, err := input.ReadLine("Type to filter, or press Enter to cancel: ")
+		if err != nil || answer == "" {
+			return ""
+		}
+		filter = answer
+	}
+}
+
+// matching returns the names containing the filter, ignoring case
+func matching(names []string, filter string) []string {
+	filter = strings.ToLower(filter)
+	var matches []string
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), filter) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
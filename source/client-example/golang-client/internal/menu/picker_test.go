@@ -0,0 +1,83 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPickName(t *testing.T) {
+	names := []string{"llama", "granite", "mistral"}
+	many := make([]string, maxPicks+5)
+	for i := range many {
+		many[i] = fmt.Sprintf("model-%02d", i)
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		names    []string
+		listErr  error
+		want     string
+		wantOut  []string
+		wantNone []string
+	}{
+		{name: "existing name", script: "granite\n", names: names, want: "granite", wantNone: []string{"1."}},
+		{name: "pick by number", script: "\n2\n", names: names, want: "llama", wantOut: []string{"  1. granite", "  2. llama", "  3. mistral"}},
+		{name: "unknown name shows matches", script: "gran\n1\n", names: names, want: "granite", wantOut: []string{"No model named 'gran'.", "  1. granite"}, wantNone: []string{"llama"}},
+		{name: "filter ignores case", script: "\nMIS\n1\n", names: names, want: "mistral"},
+		{name: "invalid number", script: "\n9\n3\n", names: names, want: "mistral", wantOut: []string{"Invalid selection: 9"}},
+		{name: "no match then filter", script: "zzz\nl\n2\n", names: names, want: "mistral", wantOut: []string{"No models match 'zzz'."}},
+		{name: "cancel", script: "\n\n", names: names, want: ""},
+		{name: "end of input at prompt", script: "", names: names, want: ""},
+		{name: "end of input in list", script: "\n", names: names, want: ""},
+		{name: "nothing to pick", script: "\n", want: "", wantOut: []string{"No models found."}},
+		{name: "long list is cut", script: "\n\n", names: many, want: "", wantOut: []string{" 30. model-29", "... and 5 more"}, wantNone: []string{"model-30"}},
+		{
+			name:    "list error uses typed name",
+			script:  "granite\n",
+			listErr: errors.New("forbidden"),
+			want:    "granite",
+			wantOut: []string{"Could not list models: forbidden", "Using 'granite' as typed"},
+		},
+		{
+			name:    "list error asks for a name",
+			script:  "\ngranite\n",
+			listErr: errors.New("forbidden"),
+			want:    "granite",
+			wantOut: []string{"Could not list models: forbidden", "Type the model name instead: ", "Using 'granite' as typed"},
+		},
+		{
+			name:     "list error and no name",
+			script:   "\n\n",
+			listErr:  errors.New("forbidden"),
+			want:     "",
+			wantOut:  []string{"Could not list models: forbidden"},
+			wantNone: []string{"Using"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := useInput(t, tt.script)
+			list := func() ([]string, error) {
+				return append([]string(nil), tt.names...), tt.listErr
+			}
+
+			if got := PickName("Model name", "model", list); got != tt.want {
+				t.Errorf("PickName() = %q, want %q\n%s", got, tt.want, out)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("PickName() output does not contain %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.wantNone {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("PickName() output contains %q:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetName prompts for a resource name. It returns "" at the end of the input.
+func GetName(prompt string) string {
+	name, _ := input.ReadLine(prompt)
+	return name
+}
+
+// GetConfirmation prompts for yes/no confirmation. The end of the input counts as no.
+func GetConfirmation(prompt string) bool {
+	response, _ := input.ReadLine(prompt + " (yes/no): ")
+	response = strings.ToLower(response)
+	return response == "yes" || response == "y"
+}
+
+// ConfirmName asks the user to type the name of the object to delete, so a
+// single stray "y" cannot confirm it. The end of the input counts as no.
+func ConfirmName(kind, name string) bool {
+	response, _ := input.ReadLine(fmt.Sprintf("Type the %s name '%s' to confirm: ", kind, name))
+	if response != name {
+		if response != "" {
+			fmt.Fprintf(input.out, "'%s' does not match.\n", response)
+		}
+		return false
+	}
+	return true
+}
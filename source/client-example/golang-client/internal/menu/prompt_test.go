@@ -0,0 +1,27 @@
+package menu
+
+import "testing"
+
+func TestConfirmName(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{name: "typed name", script: "acme\n", want: true},
+		{name: "typed with spaces", script: "  acme \n", want: true},
+		{name: "other name", script: "other\n"},
+		{name: "yes is not the name", script: "yes\n"},
+		{name: "empty", script: "\n"},
+		{name: "end of input", script: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useInput(t, tt.script)
+			if got := ConfirmName("project", "acme"); got != tt.want {
+				t.Errorf("ConfirmName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,17 +3,112 @@ package clusterrolebindings
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// crbKind describes how cluster role bindings are printed
+var crbKind = output.Kind{
+	Name: "clusterrolebinding.rbac.authorization.k8s.io",
+	Columns: []output.Column{
+		{Header: "ROLE", Value: func(obj map[string]interface{}) string {
+			return output.Field(obj, "roleRef", "kind") + "/" + output.Field(obj, "roleRef", "name")
+		}},
+		{Header: "AGE", Value: output.Age},
+		{Header: "SUBJECTS", Wide: true, Value: subjects},
+		{Header: "LABELS", Wide: true, Value: output.Labels},
+	},
+}
+
+// subjects returns the binding's subjects as kind/name pairs
+func subjects(obj map[string]interface{}) string {
+	list, _, _ := unstructured.NestedSlice(obj, "subjects")
+	names := make([]string, 0, len(list))
+	for _, subject := range list {
+		if s, ok := subject.(map[string]interface{}); ok {
+			names = append(names, fmt.Sprintf("%v/%v", s["kind"], s["name"]))
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ListClusterRoleBindings retrieves and returns the names of all cluster role bindings
+func ListClusterRoleBindings(ctx context.Context, sess *session.Session) ([]string, error) {
+	crbs, err := sess.Clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster role bindings: %w", err)
+	}
+
+	names := make([]string, 0, len(crbs.Items))
+	for _, crb := range crbs.Items {
+		names = append(names, crb.Name)
+	}
+
+	return names, nil
+}
+
+// List returns the cluster role bindings and how to print them
+func List(ctx context.Context, sess *session.Session) (output.Kind, []map[string]interface{}, error) {
+	crbs, err := sess.Clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return crbKind, nil, fmt.Errorf("error listing cluster role bindings: %w", err)
+	}
+
+	items := make([]map[string]interface{}, 0, len(crbs.Items))
+	for i := range crbs.Items {
+		item, err := output.ToMap(&crbs.Items[i])
+		if err != nil {
+			return crbKind, nil, err
+		}
+		items = append(items, item)
+	}
+
+	return crbKind, items, nil
+}
+
+// HandleList handles the list action for cluster role bindings
+func HandleList(ctx context.Context, sess *session.Session, format output.Format) error {
+	kind, items, err := List(ctx, sess)
+	if err != nil {
+		return err
+	}
+
+	return output.PrintList(sess.Out, format, kind, items)
+}
+
+// HandleGet handles the get action for a specific cluster role binding
+func HandleGet(ctx context.Context, sess *session.Session, name string, format output.Format) error {
+	crb, err := sess.Clientset.RbacV1().ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting cluster role binding: %w", err)
+	}
+
+	item, err := output.ToMap(crb)
+	if err != nil {
+		return err
+	}
+
+	return output.PrintObject(sess.Out, format, crbKind, item)
+}
+
+// annotatePermission is needed to annotate the named cluster role binding, or any when name is empty
+func annotatePermission(name string) access.Permission {
+	return access.Permission{Verb: "update", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Name: name}
+}
+
 // HandleAddAnnotation adds the annotation "bakerapps.net/test": "annotated" to a cluster role binding
-func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
+func HandleAddAnnotation(ctx context.Context, sess *session.Session, name string) error {
+	if err := access.Check(ctx, sess, annotatePermission(name)); err != nil {
+		return err
+	}
 
 	// Get the existing cluster role binding
-	crb, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
+	crb, err := sess.Clientset.RbacV1().ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("error getting cluster role binding: %w", err)
 	}
@@ -27,14 +122,14 @@ func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
 	crb.Annotations["bakerapps.net/test"] = "annotated"
 
 	// Update the cluster role binding
-	updated, err := clientset.RbacV1().ClusterRoleBindings().Update(ctx, crb, metav1.UpdateOptions{})
+	updated, err := sess.Clientset.RbacV1().ClusterRoleBindings().Update(ctx, crb, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("error updating cluster role binding with annotation: %w", err)
 	}
 
-	fmt.Printf("\n✓ Successfully added annotation to cluster role binding: %s\n", updated.Name)
-	fmt.Printf("  Annotation: bakerapps.net/test = annotated\n")
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\n✓ Successfully added annotation to cluster role binding: %s\n", updated.Name)
+	fmt.Fprintf(sess.Status, "  Annotation: bakerapps.net/test = annotated\n")
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
@@ -1,51 +1,87 @@
 package clusterrolebindings
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/bryon/ocp-lister/internal/access"
 	"github.com/bryon/ocp-lister/internal/menu"
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// HandleCRUDMenu handles the CRUD menu for cluster role bindings
-func HandleCRUDMenu(clientset *kubernetes.Clientset) {
-	crudMenu := menu.NewCRUDMenu("Cluster Role Bindings")
-
-	for {
-		choice := crudMenu.DisplayAndGetChoice()
-
-		switch choice {
-		case "1": // List
-			fmt.Println("List cluster role bindings - Not yet implemented")
-
-		case "2": // Get
-			name := menu.GetName("Enter cluster role binding name: ")
-			fmt.Printf("Get cluster role binding %s - Not yet implemented\n", name)
-
-		case "3": // Create
-			name := menu.GetName("Enter cluster role binding name to create: ")
-			fmt.Printf("Create cluster role binding %s - Not yet implemented\n", name)
-
-		case "4": // Update
-			name := menu.GetName("Enter cluster role binding name to update: ")
-			fmt.Printf("Update cluster role binding %s - Not yet implemented\n", name)
-
-		case "5": // Delete
-			name := menu.GetName("Enter cluster role binding name to delete: ")
-			fmt.Printf("Delete cluster role binding %s - Not yet implemented\n", name)
-
-		case "6": // Add Annotation
-			name := menu.GetName("Enter cluster role binding name to annotate: ")
-			if name == "" {
-				fmt.Println("Cluster role binding name cannot be empty")
-				continue
-			}
-			if err := HandleAddAnnotation(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "B": // Back
-			return
-		}
+func init() {
+	menu.Register(menu.Item{Key: "D", Title: "Cluster Role Bindings", Order: 40, Submenu: crudMenu})
+}
+
+// crudMenu lists the cluster role binding actions
+var crudMenu = menu.NewMenu("Cluster Role Bindings",
+	menu.Item{Key: "1", Title: "List (Read)", Action: handleList},
+	menu.Item{Key: "2", Title: "Get (Read by name)", Action: handleGet},
+	menu.Item{Key: "3", Title: "Create", Action: func(state *menu.State) {
+		name := menu.GetName("Enter cluster role binding name to create: ")
+		fmt.Printf("Create cluster role binding %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "4", Title: "Update", Action: func(state *menu.State) {
+		name := menu.GetName("Enter cluster role binding name to update: ")
+		fmt.Printf("Update cluster role binding %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "5", Title: "Delete", Action: func(state *menu.State) {
+		name := menu.GetName("Enter cluster role binding name to delete: ")
+		fmt.Printf("Delete cluster role binding %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "6", Title: "Add Annotation", Action: handleAddAnnotation, Permission: func() access.Permission {
+		return annotatePermission("")
+	}},
+)
+
+// handleList lists cluster role bindings as a table
+func handleList(state *menu.State) {
+	sess := state.Session
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleList(ctx, sess, output.Format{Kind: output.Table})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleGet shows a cluster role binding as JSON
+func handleGet(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter cluster role binding name", "cluster role binding", crbNames(sess))
+	if name == "" {
+		fmt.Println("Cluster role binding name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleGet(ctx, sess, name, output.Format{Kind: output.JSON})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleAddAnnotation adds the test annotation to a cluster role binding
+func handleAddAnnotation(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter cluster role binding name to annotate", "cluster role binding", crbNames(sess))
+	if name == "" {
+		fmt.Println("Cluster role binding name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleAddAnnotation(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// crbNames lists cluster role bindings for menu.PickName
+func crbNames(sess *session.Session) func() ([]string, error) {
+	return func() (names []string, err error) {
+		err = sess.Run(func(ctx context.Context) error {
+			names, err = ListClusterRoleBindings(ctx, sess)
+			return err
+		})
+		return names, err
 	}
 }
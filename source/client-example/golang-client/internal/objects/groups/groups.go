@@ -0,0 +1,93 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/objects/tiers"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// getGroupResource returns the GVR for Group resources
+func getGroupResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "user.openshift.io",
+		Version:  "v1",
+		Resource: "groups",
+	}
+}
+
+// groupKind describes how groups are printed. The TIERS column shows the MaaS
+// tiers each group is mapped to, and is left out when the mapping is unreadable.
+func groupKind(tierList []tiers.Tier) output.Kind {
+	kind := output.Kind{
+		Name: "group.user.openshift.io",
+		Columns: []output.Column{
+			{Header: "USERS", Value: func(obj map[string]interface{}) string { return output.StringSlice(obj, "users") }},
+			{Header: "AGE", Value: output.Age},
+			{Header: "LABELS", Wide: true, Value: output.Labels},
+		},
+	}
+
+	if tierList != nil {
+		kind.Columns = append(kind.Columns, output.Column{Header: "TIERS", Value: func(obj map[string]interface{}) string {
+			return strings.Join(tiers.ForGroup(tierList, output.Field(obj, "metadata", "name")), ",")
+		}})
+	}
+
+	return kind
+}
+
+// List returns the groups and how to print them
+func List(ctx context.Context, sess *session.Session) (output.Kind, []map[string]interface{}, error) {
+	groupList, err := sess.Dynamic.Resource(getGroupResource()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return groupKind(nil), nil, fmt.Errorf("error listing groups: %w", err)
+	}
+
+	// Tiers are optional: most users cannot read the mapping in maas-api
+	tierList, _ := tiers.ListTiers(ctx, sess)
+
+	return groupKind(tierList), output.Items(groupList), nil
+}
+
+// HandleList handles the list action for groups
+func HandleList(ctx context.Context, sess *session.Session, format output.Format) error {
+	kind, items, err := List(ctx, sess)
+	if err != nil {
+		return err
+	}
+
+	return output.PrintList(sess.Out, format, kind, items)
+}
+
+// HandleGet handles the get action for a specific group
+func HandleGet(ctx context.Context, sess *session.Session, name string, format output.Format) error {
+	group, err := sess.Dynamic.Resource(getGroupResource()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting group: %w", err)
+	}
+
+	tierList, _ := tiers.ListTiers(ctx, sess)
+
+	return output.PrintObject(sess.Out, format, groupKind(tierList), group.Object)
+}
+
+// ListGroups retrieves and returns the names of all groups
+func ListGroups(ctx context.Context, sess *session.Session) ([]string, error) {
+	groupList, err := sess.Dynamic.Resource(getGroupResource()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	names := make([]string, 0, len(groupList.Items))
+	for _, group := range groupList.Items {
+		names = append(names, group.GetName())
+	}
+
+	return names, nil
+}
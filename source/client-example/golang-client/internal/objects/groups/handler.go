@@ -1,49 +1,76 @@
 package groups
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bryon/ocp-lister/internal/menu"
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// HandleCRUDMenu handles the CRUD menu for groups
-func HandleCRUDMenu(clientset *kubernetes.Clientset) {
-	crudMenu := menu.NewCRUDMenu("Groups")
-
-	for {
-		choice := crudMenu.DisplayAndGetChoice()
-
-		switch choice {
-		case "1": // List
-			fmt.Println("List groups - Not yet implemented")
-
-		case "2": // Get
-			name := menu.GetName("Enter group name: ")
-			fmt.Printf("Get group %s - Not yet implemented\n", name)
-
-		case "3": // Create
-			name := menu.GetName("Enter group name to create: ")
-			fmt.Printf("Create group %s - Not yet implemented\n", name)
+func init() {
+	menu.Register(menu.Item{Key: "B", Title: "Groups", Order: 20, Submenu: crudMenu})
+}
 
-		case "4": // Update
-			name := menu.GetName("Enter group name to update: ")
-			fmt.Printf("Update group %s - Not yet implemented\n", name)
+// crudMenu lists the group actions
+var crudMenu = menu.NewMenu("Groups",
+	menu.Item{Key: "1", Title: "List (Read)", Action: handleList},
+	menu.Item{Key: "2", Title: "Get (Read by name)", Action: handleGet},
+	menu.Item{Key: "3", Title: "Create", Action: func(state *menu.State) {
+		name := menu.GetName("Enter group name to create: ")
+		fmt.Printf("Create group %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "4", Title: "Update", Action: func(state *menu.State) {
+		name := menu.GetName("Enter group name to update: ")
+		fmt.Printf("Update group %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "5", Title: "Delete", Action: func(state *menu.State) {
+		name := menu.GetName("Enter group name to delete: ")
+		fmt.Printf("Delete group %s - Not yet implemented\n", name)
+	}},
+	menu.Item{Key: "6", Title: "Add Annotation", Action: func(state *menu.State) {
+		name := menu.GetName("Enter group name to annotate: ")
+		if name == "" {
+			fmt.Println("Group name cannot be empty")
+			return
+		}
+		fmt.Printf("Add annotation to group %s - Not yet implemented (requires OpenShift client)\n", name)
+	}},
+)
 
-		case "5": // Delete
-			name := menu.GetName("Enter group name to delete: ")
-			fmt.Printf("Delete group %s - Not yet implemented\n", name)
+// handleList lists groups as a table
+func handleList(state *menu.State) {
+	sess := state.Session
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleList(ctx, sess, output.Format{Kind: output.Table})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "6": // Add Annotation
-			name := menu.GetName("Enter group name to annotate: ")
-			if name == "" {
-				fmt.Println("Group name cannot be empty")
-				continue
-			}
-			fmt.Printf("Add annotation to group %s - Not yet implemented (requires OpenShift client)\n", name)
+// handleGet shows a group as JSON
+func handleGet(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter group name", "group", groupNames(sess))
+	if name == "" {
+		fmt.Println("Group name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleGet(ctx, sess, name, output.Format{Kind: output.JSON})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "B": // Back
-			return
-		}
+// groupNames lists groups for menu.PickName
+func groupNames(sess *session.Session) func() ([]string, error) {
+	return func() (names []string, err error) {
+		err = sess.Run(func(ctx context.Context) error {
+			names, err = ListGroups(ctx, sess)
+			return err
+		})
+		return names, err
 	}
 }
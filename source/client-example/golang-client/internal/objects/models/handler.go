@@ -1,85 +1,123 @@
 package models
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/bryon/ocp-lister/internal/access"
 	"github.com/bryon/ocp-lister/internal/menu"
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// HandleModelMenu handles the model menu with Deploy and Undeploy options
-func HandleModelMenu(clientset *kubernetes.Clientset) {
-	modelMenu := menu.NewMenu("Model Management")
-	modelMenu.AddOption("1", "Deploy")
-	modelMenu.AddOption("2", "Undeploy")
-	modelMenu.AddOption("3", "List")
-	modelMenu.AddOption("4", "Get")
-	modelMenu.AddOption("B", "Back to main menu")
+func init() {
+	menu.Register(menu.Item{Key: "E", Title: "Models", Order: 50, Submenu: modelMenu})
+}
 
-	for {
-		choice := modelMenu.DisplayAndGetChoice()
+// modelMenu lists the model actions
+var modelMenu = menu.NewMenu("Models",
+	menu.Item{Key: "1", Title: "Deploy", Action: handleDeploy, Permission: func() access.Permission {
+		return deployPermission("")
+	}},
+	menu.Item{Key: "2", Title: "Undeploy", Action: handleUndeploy},
+	menu.Item{Key: "3", Title: "List", Action: handleList},
+	menu.Item{Key: "4", Title: "Get", Action: handleGet},
+)
 
-		switch choice {
-		case "1": // Deploy
-			name := menu.GetName("Enter model name to deploy: ")
-			if name == "" {
-				fmt.Println("Model name cannot be empty")
-				continue
-			}
-			namespace := menu.GetName("Enter namespace: ")
-			if namespace == "" {
-				fmt.Println("Namespace cannot be empty")
-				continue
-			}
-			if err := HandleDeploy(clientset, name, namespace); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleDeploy prompts for a name and namespace and deploys the model
+func handleDeploy(state *menu.State) {
+	sess := state.Session
+	name := menu.GetName("Enter model name to deploy: ")
+	if name == "" {
+		fmt.Println("Model name cannot be empty")
+		return
+	}
+	namespace := menu.GetName("Enter namespace: ")
+	if namespace == "" {
+		fmt.Println("Namespace cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleDeploy(ctx, sess, name, namespace)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "2": // Undeploy
-			name := menu.GetName("Enter model name to undeploy: ")
-			if name == "" {
-				fmt.Println("Model name cannot be empty")
-				continue
-			}
-			namespace := menu.GetName("Enter namespace: ")
-			if namespace == "" {
-				fmt.Println("Namespace cannot be empty")
-				continue
-			}
-			// Get confirmation before undeploying
-			if !menu.GetConfirmation(fmt.Sprintf("Are you sure you want to undeploy model '%s' in namespace '%s'", name, namespace)) {
-				fmt.Println("Undeploy cancelled.")
-				continue
-			}
-			if err := HandleUndeploy(clientset, name, namespace); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleUndeploy undeploys a model after confirmation
+func handleUndeploy(state *menu.State) {
+	sess := state.Session
+	namespace := menu.GetName("Enter namespace: ")
+	if namespace == "" {
+		fmt.Println("Namespace cannot be empty")
+		return
+	}
+	name := menu.PickName("Enter model name to undeploy", "model", modelNames(sess, namespace))
+	if name == "" {
+		fmt.Println("Model name cannot be empty")
+		return
+	}
+	// Refuse protected models before asking
+	if err := sess.Run(func(ctx context.Context) error {
+		_, err := undeployableModel(ctx, sess, name, namespace)
+		return err
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// Get confirmation before undeploying
+	fmt.Printf("Undeploying model '%s' in namespace '%s'.\n", name, namespace)
+	if !menu.ConfirmName("model", name) {
+		fmt.Println("Undeploy cancelled.")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleUndeploy(ctx, sess, name, namespace)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "3": // List
-			namespace := menu.GetName("Enter namespace (or press Enter for 'llm'): ")
-			if namespace == "" {
-				namespace = "llm"
-			}
-			if err := HandleList(clientset, namespace); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleList lists the models of a namespace as a table
+func handleList(state *menu.State) {
+	sess := state.Session
+	namespace := menu.GetName(fmt.Sprintf("Enter namespace (or press Enter for '%s'): ", sess.Settings.ModelNamespace))
+	if namespace == "" {
+		namespace = sess.Settings.ModelNamespace
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleList(ctx, sess, namespace, output.Format{Kind: output.Table})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "4": // Get
-			name := menu.GetName("Enter model name: ")
-			if name == "" {
-				fmt.Println("Model name cannot be empty")
-				continue
-			}
-			namespace := menu.GetName("Enter namespace (or press Enter for 'llm'): ")
-			if namespace == "" {
-				namespace = "llm"
-			}
-			if err := HandleGet(clientset, name, namespace); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleGet shows a model as JSON
+func handleGet(state *menu.State) {
+	sess := state.Session
+	namespace := menu.GetName(fmt.Sprintf("Enter namespace (or press Enter for '%s'): ", sess.Settings.ModelNamespace))
+	if namespace == "" {
+		namespace = sess.Settings.ModelNamespace
+	}
+	name := menu.PickName("Enter model name", "model", modelNames(sess, namespace))
+	if name == "" {
+		fmt.Println("Model name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleGet(ctx, sess, name, namespace, output.Format{Kind: output.JSON})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "B": // Back
-			return
-		}
+// modelNames lists the models in a namespace for menu.PickName
+func modelNames(sess *session.Session, namespace string) func() ([]string, error) {
+	return func() (names []string, err error) {
+		err = sess.Run(func(ctx context.Context) error {
+			names, err = ListModels(ctx, sess, namespace)
+			return err
+		})
+		return names, err
 	}
 }
@@ -4,39 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
-	"github.com/bryon/ocp-lister/internal/auth"
-	"github.com/bryon/ocp-lister/internal/client"
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/protect"
+	"github.com/bryon/ocp-lister/internal/session"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
 )
 
-// getModelClient creates a dynamic client for LLMInferenceService resources
-func getModelClient(clientset *kubernetes.Clientset) (dynamic.Interface, error) {
-	// Get auth config to retrieve server, username, password
-	authConfig, err := auth.LoadFromEnv()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load auth config: %w", err)
-	}
-
-	// Get REST config
-	config, err := client.GetRESTConfig(authConfig.Server, authConfig.Username, authConfig.Password)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get REST config: %w", err)
-	}
-
-	// Create dynamic client
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
-	}
-
-	return dynamicClient, nil
-}
-
 // getModelResource returns the GVR for LLMInferenceService resources
 func getModelResource() schema.GroupVersionResource {
 	return schema.GroupVersionResource{
@@ -46,24 +24,26 @@ func getModelResource() schema.GroupVersionResource {
 	}
 }
 
+// deployPermission is needed to deploy a model into the namespace
+func deployPermission(namespace string) access.Permission {
+	return access.Permission{Verb: "create", Group: "serving.kserve.io", Resource: "llminferenceservices", Namespace: namespace}
+}
+
 // HandleDeploy deploys an LLMInferenceService with the specified name and namespace
 // All other fields are set exactly as in the GitHub example
-func HandleDeploy(clientset *kubernetes.Clientset, name, namespace string) error {
-	ctx := context.Background()
+func HandleDeploy(ctx context.Context, sess *session.Session, name, namespace string) error {
+	if err := access.Check(ctx, sess, deployPermission(namespace)); err != nil {
+		return err
+	}
 
 	// Check if namespace exists
-	_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	_, err := sess.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("namespace '%s' does not exist: %w", namespace, err)
 	}
 
-	dynamicClient, err := getModelClient(clientset)
-	if err != nil {
-		return err
-	}
-
 	// Check if model already exists
-	_, err = dynamicClient.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	_, err = sess.Dynamic.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err == nil {
 		return fmt.Errorf("model '%s' already exists in namespace '%s'", name, namespace)
 	}
@@ -75,7 +55,7 @@ func HandleDeploy(clientset *kubernetes.Clientset, name, namespace string) error
 			"kind":       "LLMInferenceService",
 			"metadata": map[string]interface{}{
 				"annotations": map[string]interface{}{
-					"alpha.maas.opendatahub.io/tiers": `["redhat-users-tier"]`,
+					tiersAnnotation: `["redhat-users-tier"]`,
 				},
 				"name":      name,
 				"namespace": namespace,
@@ -90,8 +70,8 @@ func HandleDeploy(clientset *kubernetes.Clientset, name, namespace string) error
 					"gateway": map[string]interface{}{
 						"refs": []interface{}{
 							map[string]interface{}{
-								"name":      "maas-default-gateway",
-								"namespace": "openshift-ingress",
+								"name":      sess.Settings.GatewayName,
+								"namespace": sess.Settings.GatewayNamespace,
 							},
 						},
 					},
@@ -167,110 +147,141 @@ func HandleDeploy(clientset *kubernetes.Clientset, name, namespace string) error
 	}
 
 	// Create the model
-	created, err := dynamicClient.Resource(getModelResource()).Namespace(namespace).Create(ctx, model, metav1.CreateOptions{})
+	created, err := sess.Dynamic.Resource(getModelResource()).Namespace(namespace).Create(ctx, model, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to deploy model: %w", err)
 	}
 
 	createdName, _, _ := unstructured.NestedString(created.Object, "metadata", "name")
-	fmt.Printf("\n✓ Successfully deployed model: %s\n", createdName)
-	fmt.Printf("  Namespace: %s\n", namespace)
-	fmt.Printf("  API Version: serving.kserve.io/v1alpha1\n")
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\n✓ Successfully deployed model: %s\n", createdName)
+	fmt.Fprintf(sess.Status, "  Namespace: %s\n", namespace)
+	fmt.Fprintf(sess.Status, "  API Version: serving.kserve.io/v1alpha1\n")
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
 
-// HandleUndeploy removes an LLMInferenceService
-func HandleUndeploy(clientset *kubernetes.Clientset, name, namespace string) error {
-	ctx := context.Background()
-
-	dynamicClient, err := getModelClient(clientset)
+// undeployableModel gets a model, refusing models labelled as protected
+func undeployableModel(ctx context.Context, sess *session.Session, name, namespace string) (*unstructured.Unstructured, error) {
+	model, err := sess.Dynamic.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error getting model: %w", err)
+	}
+	if err := protect.Check("model", model); err != nil {
+		return nil, err
 	}
+	return model, nil
+}
 
+// HandleUndeploy removes an LLMInferenceService
+func HandleUndeploy(ctx context.Context, sess *session.Session, name, namespace string) error {
 	// Get model first to verify it exists
-	model, err := dynamicClient.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	model, err := undeployableModel(ctx, sess, name, namespace)
 	if err != nil {
-		return fmt.Errorf("error getting model: %w", err)
+		return err
 	}
 
 	modelName, _, _ := unstructured.NestedString(model.Object, "metadata", "name")
 
 	// Show model details before deletion
-	fmt.Printf("\nModel to undeploy: %s\n", modelName)
-	fmt.Printf("Namespace: %s\n", namespace)
-	fmt.Println("\n⚠️  WARNING: This will undeploy the model!")
-	fmt.Println("   This action cannot be undone.")
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\nModel to undeploy: %s\n", modelName)
+	fmt.Fprintf(sess.Status, "Namespace: %s\n", namespace)
+	fmt.Fprintln(sess.Status, "\n⚠️  WARNING: This will undeploy the model!")
+	fmt.Fprintln(sess.Status, "   This action cannot be undone.")
+	fmt.Fprintln(sess.Status)
 
 	// Delete the model
-	err = dynamicClient.Resource(getModelResource()).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	err = sess.Dynamic.Resource(getModelResource()).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("error undeploying model: %w", err)
 	}
 
-	fmt.Printf("✓ Successfully undeployed model: %s\n", name)
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "✓ Successfully undeployed model: %s\n", name)
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
 
-// HandleList lists all LLMInferenceService models in the specified namespace
-func HandleList(clientset *kubernetes.Clientset, namespace string) error {
-	ctx := context.Background()
-
-	dynamicClient, err := getModelClient(clientset)
-	if err != nil {
-		return err
-	}
+// tiersAnnotation lists the MaaS tiers allowed to use a model, as a JSON array
+const tiersAnnotation = "alpha.maas.opendatahub.io/tiers"
+
+// modelKind describes how models are printed
+var modelKind = output.Kind{
+	Name: "llminferenceservice.serving.kserve.io",
+	Columns: []output.Column{
+		{Header: "READY", Value: readyCondition},
+		{Header: "URL", Value: func(obj map[string]interface{}) string { return output.Field(obj, "status", "url") }},
+		{Header: "TIERS", Value: modelTiers},
+		{Header: "AGE", Value: output.Age},
+		{Header: "MODEL", Wide: true, Value: func(obj map[string]interface{}) string { return output.Field(obj, "spec", "model", "name") }},
+		{Header: "REPLICAS", Wide: true, Value: func(obj map[string]interface{}) string { return output.Field(obj, "spec", "replicas") }},
+		{Header: "LABELS", Wide: true, Value: output.Labels},
+	},
+}
 
-	// List models in specified namespace
-	modelList, err := dynamicClient.Resource(getModelResource()).Namespace(namespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list models: %w", err)
+// readyCondition returns the status of the model's Ready condition
+func readyCondition(obj map[string]interface{}) string {
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, condition := range conditions {
+		if c, ok := condition.(map[string]interface{}); ok && c["type"] == "Ready" {
+			return fmt.Sprint(c["status"])
+		}
 	}
+	return ""
+}
 
-	if len(modelList.Items) == 0 {
-		fmt.Printf("\nNo models found in namespace '%s'.\n", namespace)
-		fmt.Println()
-		return nil
+// modelTiers returns the tiers from the model's tiers annotation
+func modelTiers(obj map[string]interface{}) string {
+	value := output.Annotation(obj, tiersAnnotation)
+	var tiers []string
+	if err := json.Unmarshal([]byte(value), &tiers); err != nil {
+		return value
 	}
+	return strings.Join(tiers, ",")
+}
 
-	fmt.Printf("\nFound %d model(s) in namespace '%s':\n\n", len(modelList.Items), namespace)
-	for i, model := range modelList.Items {
-		name, _, _ := unstructured.NestedString(model.Object, "metadata", "name")
-		fmt.Printf("%d. %s\n", i+1, name)
+// List returns the LLMInferenceService models in a namespace and how to print them
+func List(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+	modelList, err := sess.Dynamic.Resource(getModelResource()).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return modelKind, nil, fmt.Errorf("failed to list models: %w", err)
 	}
-	fmt.Println()
 
-	return nil
+	return modelKind, output.Items(modelList), nil
 }
 
-// HandleGet retrieves and displays a specific model as JSON
-func HandleGet(clientset *kubernetes.Clientset, name, namespace string) error {
-	ctx := context.Background()
-
-	dynamicClient, err := getModelClient(clientset)
+// HandleList lists all LLMInferenceService models in the specified namespace
+func HandleList(ctx context.Context, sess *session.Session, namespace string, format output.Format) error {
+	kind, items, err := List(ctx, sess, namespace)
 	if err != nil {
 		return err
 	}
 
+	return output.PrintList(sess.Out, format, kind, items)
+}
+
+// HandleGet retrieves and displays a specific model
+func HandleGet(ctx context.Context, sess *session.Session, name, namespace string, format output.Format) error {
 	// Get model
-	model, err := dynamicClient.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	model, err := sess.Dynamic.Resource(getModelResource()).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("error getting model: %w", err)
 	}
 
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(model.Object, "", "  ")
+	return output.PrintObject(sess.Out, format, modelKind, model.Object)
+}
+
+// ListModels retrieves and returns the names of the models in a namespace
+func ListModels(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+	modelList, err := sess.Dynamic.Resource(getModelResource()).Namespace(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("error marshaling model to JSON: %w", err)
+		return nil, fmt.Errorf("failed to list models: %w", err)
 	}
 
-	fmt.Println("\n" + string(jsonData))
-	fmt.Println()
+	names := make([]string, 0, len(modelList.Items))
+	for _, model := range modelList.Items {
+		names = append(names, model.GetName())
+	}
 
-	return nil
+	return names, nil
 }
@@ -0,0 +1,11 @@
+// Package objects registers the menu of every object type. Import it for its
+// side effects; a new object type only needs to be added here.
+package objects
+
+import (
+	_ "github.com/bryon/ocp-lister/internal/objects/clusterrolebindings"
+	_ "github.com/bryon/ocp-lister/internal/objects/groups"
+	_ "github.com/bryon/ocp-lister/internal/objects/models"
+	_ "github.com/bryon/ocp-lister/internal/objects/projects"
+	_ "github.com/bryon/ocp-lister/internal/objects/users"
+)
@@ -0,0 +1,408 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/objects/models"
+	"github.com/bryon/ocp-lister/internal/protect"
+	"github.com/bryon/ocp-lister/internal/session"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// DefaultDeleteWaitTimeout bounds how long a delete waits for the project to go
+const DefaultDeleteWaitTimeout = 5 * time.Minute
+
+const (
+	// deletePollInterval is how often a waiting delete checks the project
+	deletePollInterval = 3 * time.Second
+	// reportTimeout is the time left after the wait to find what is blocking
+	reportTimeout = 30 * time.Second
+	// inventoryNames is the number of names shown for each kind in the inventory
+	inventoryNames = 5
+)
+
+// DeleteOptions controls how a project is deleted
+type DeleteOptions struct {
+	// Wait waits until the project is gone, showing what remains, and reports
+	// what blocks it if it is still terminating after WaitTimeout
+	Wait        bool
+	WaitTimeout time.Duration
+}
+
+// waitTimeout returns the wait time limit, DefaultDeleteWaitTimeout if unset
+func (o DeleteOptions) waitTimeout() time.Duration {
+	if o.WaitTimeout > 0 {
+		return o.WaitTimeout
+	}
+	return DefaultDeleteWaitTimeout
+}
+
+// OperationTimeout returns the time limit the delete needs as an operation:
+// the wait plus time for the report, or 0 to use the configured limit
+func (o DeleteOptions) OperationTimeout() time.Duration {
+	if !o.Wait {
+		return 0
+	}
+	return o.waitTimeout() + reportTimeout
+}
+
+// HandleDelete handles the delete action for projects. It shows what will be
+// destroyed, deletes the project and, if asked, waits for it to be gone.
+func HandleDelete(ctx context.Context, sess *session.Session, name string, opts DeleteOptions) error {
+	if err := DescribeForDeletion(ctx, sess, name); err != nil {
+		return err
+	}
+	return Delete(ctx, sess, name, opts)
+}
+
+// DescribeForDeletion shows the project and an inventory of its models, PVCs
+// and secrets, so it can be shown before asking for confirmation. It refuses
+// protected projects.
+func DescribeForDeletion(ctx context.Context, sess *session.Session, name string) error {
+	// First, verify the project exists
+	namespace, err := deletableProject(ctx, sess, name)
+	if err != nil {
+		return err
+	}
+
+	// Show project details before deletion
+	fmt.Fprintf(sess.Status, "\nProject to delete: %s\n", namespace.Name)
+	fmt.Fprintf(sess.Status, "Status: %s\n", namespace.Status.Phase)
+	fmt.Fprintf(sess.Status, "Created: %s\n", namespace.CreationTimestamp.Format("2006-01-02 15:04:05"))
+
+	fmt.Fprintln(sess.Status, "Contents:")
+	for _, kind := range []struct {
+		title string
+		list  func() ([]string, error)
+	}{
+		{"Models (LLMInferenceService)", func() ([]string, error) { return models.ListModels(ctx, sess, name) }},
+		{"PersistentVolumeClaims", func() ([]string, error) { return pvcNames(ctx, sess, name) }},
+		{"Secrets", func() ([]string, error) { return secretNames(ctx, sess, name) }},
+	} {
+		names, err := kind.list()
+		if apierrors.IsNotFound(err) {
+			// The resource type is not installed
+			names, err = nil, nil
+		}
+		if err != nil {
+			fmt.Fprintf(sess.Status, "  %s: unknown (%v)\n", kind.title, err)
+			continue
+		}
+		fmt.Fprintf(sess.Status, "  %s: %d%s\n", kind.title, len(names), sample(names))
+	}
+
+	fmt.Fprintln(sess.Status, "\n⚠️  WARNING: This will delete the project and all resources within it!")
+	fmt.Fprintln(sess.Status, "   This action cannot be undone.")
+	fmt.Fprintln(sess.Status)
+
+	return nil
+}
+
+// deletableProject gets the project to delete, refusing protected ones
+func deletableProject(ctx context.Context, sess *session.Session, name string) (*corev1.Namespace, error) {
+	namespace, err := sess.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting project: %w", err)
+	}
+	if err := protect.CheckNamespace(sess.Settings.ProtectedNamespaces, namespace); err != nil {
+		return nil, err
+	}
+	return namespace, nil
+}
+
+// Delete deletes the project and, if asked, waits for it to be gone. Unlike
+// HandleDelete it does not show the project first.
+func Delete(ctx context.Context, sess *session.Session, name string, opts DeleteOptions) error {
+	if _, err := deletableProject(ctx, sess, name); err != nil {
+		return err
+	}
+
+	// Delete the namespace
+	err := sess.Clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	fmt.Fprintf(sess.Status, "✓ Successfully initiated deletion of project: %s\n", name)
+	if !opts.Wait {
+		fmt.Fprintln(sess.Status, "  Note: Project deletion is asynchronous and may take some time to complete.")
+		fmt.Fprintln(sess.Status)
+		return nil
+	}
+
+	return waitForDeletion(ctx, sess, name, opts.waitTimeout())
+}
+
+// waitForDeletion polls the project until it is gone, printing the remaining
+// content whenever it changes. If the project outlives the timeout it reports
+// the objects and finalizers holding it.
+func waitForDeletion(ctx context.Context, sess *session.Session, name string, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(deletePollInterval)
+	defer ticker.Stop()
+
+	fmt.Fprintf(sess.Status, "Waiting up to %s for %s to be deleted...\n", timeout, name)
+	start := time.Now()
+	last := ""
+	for {
+		namespace, err := sess.Clientset.CoreV1().Namespaces().Get(waitCtx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(sess.Status, "✓ Project %s deleted after %s\n", name, time.Since(start).Round(time.Second))
+			fmt.Fprintln(sess.Status)
+			return nil
+		}
+		if err != nil && waitCtx.Err() == nil {
+			return fmt.Errorf("error checking project: %w", err)
+		}
+		if err == nil {
+			if progress := describeRemaining(namespace); progress != last {
+				fmt.Fprintf(sess.Status, "  [%s] %s\n", time.Since(start).Round(time.Second), progress)
+				last = progress
+			}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			reportBlocking(ctx, sess, name)
+			return fmt.Errorf("project %s is still terminating after %s", name, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// remaining is a count from a namespace condition, e.g. 2 pods or 1 finalizer
+type remaining struct {
+	name  string
+	count int
+}
+
+// parseRemaining reads the namespace controller's condition messages, e.g.
+// "Some resources are remaining: pods has 2 resource instances, ..." or
+// "Some content in the namespace has finalizers remaining: x in 1 resource instances, ..."
+func parseRemaining(message, separator string) []remaining {
+	i := strings.Index(message, ": ")
+	if i < 0 {
+		return nil
+	}
+
+	var result []remaining
+	for _, part := range strings.Split(message[i+2:], ", ") {
+		fields := strings.SplitN(part, separator, 2)
+		if len(fields) != 2 {
+			continue
+		}
+		var count int
+		if words := strings.Fields(fields[1]); len(words) > 0 {
+			count, _ = strconv.Atoi(words[0])
+		}
+		result = append(result, remaining{name: fields[0], count: count})
+	}
+	return result
+}
+
+// condition returns the message of a namespace condition that is true, or ""
+func condition(namespace *corev1.Namespace, conditionType corev1.NamespaceConditionType) string {
+	for _, c := range namespace.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// describeRemaining summarises what a terminating namespace still holds. The
+// content and finalizer conditions are read separately, as either may be
+// present without the other.
+func describeRemaining(namespace *corev1.Namespace) string {
+	var parts []string
+	if content := remainingContent(namespace); len(content) > 0 {
+		parts = append(parts, "remaining: "+strings.Join(content, ", "))
+	}
+	if finalizers := remainingFinalizers(namespace); len(finalizers) > 0 {
+		parts = append(parts, "waiting on finalizers: "+strings.Join(finalizers, ", "))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%s, removing content", namespace.Status.Phase)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// remainingContent lists the NamespaceContentRemaining counts, e.g. "2 pods"
+func remainingContent(namespace *corev1.Namespace) []string {
+	var content []string
+	for _, r := range parseRemaining(condition(namespace, corev1.NamespaceContentRemaining), " has ") {
+		content = append(content, fmt.Sprintf("%d %s", r.count, r.name))
+	}
+	return content
+}
+
+// remainingFinalizers lists the NamespaceFinalizersRemaining finalizers with
+// the number of objects holding each, e.g. "kubernetes.io/pvc-protection (1)"
+func remainingFinalizers(namespace *corev1.Namespace) []string {
+	var finalizers []string
+	for _, r := range parseRemaining(condition(namespace, corev1.NamespaceFinalizersRemaining), " in ") {
+		finalizers = append(finalizers, fmt.Sprintf("%s (%d)", r.name, r.count))
+	}
+	return finalizers
+}
+
+// reportBlocking lists what keeps a project terminating: failed deletion
+// conditions, the namespace's own finalizers and every remaining object with
+// its finalizers
+func reportBlocking(ctx context.Context, sess *session.Session, name string) {
+	namespace, err := sess.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		fmt.Fprintf(sess.Status, "⚠️  Could not check what is blocking %s: %v\n", name, err)
+		return
+	}
+
+	fmt.Fprintf(sess.Status, "\n⚠️  Project %s is still %s. Blocking the deletion:\n", name, namespace.Status.Phase)
+
+	for _, failure := range []corev1.NamespaceConditionType{
+		corev1.NamespaceDeletionDiscoveryFailure,
+		corev1.NamespaceDeletionGVParsingFailure,
+		corev1.NamespaceDeletionContentFailure,
+	} {
+		if message := condition(namespace, failure); message != "" {
+			fmt.Fprintf(sess.Status, "  %s: %s\n", failure, message)
+		}
+	}
+	if len(namespace.Spec.Finalizers) > 0 || len(namespace.Finalizers) > 0 {
+		var finalizers []string
+		for _, f := range namespace.Spec.Finalizers {
+			finalizers = append(finalizers, string(f))
+		}
+		finalizers = append(finalizers, namespace.Finalizers...)
+		fmt.Fprintf(sess.Status, "  Namespace finalizers: %s\n", strings.Join(finalizers, ", "))
+	}
+	if finalizers := remainingFinalizers(namespace); len(finalizers) > 0 {
+		fmt.Fprintf(sess.Status, "  Finalizers remaining on content: %s\n", strings.Join(finalizers, ", "))
+	}
+
+	content := parseRemaining(condition(namespace, corev1.NamespaceContentRemaining), " has ")
+	if held := parseRemaining(condition(namespace, corev1.NamespaceFinalizersRemaining), " in "); len(content) == 0 && len(held) > 0 {
+		// Only the finalizers are reported, so look for the objects holding them
+		reportFinalized(ctx, sess, name, held)
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(sess.Discovery))
+	for _, r := range content {
+		gvr, err := mapper.ResourceFor(schema.ParseGroupResource(r.name).WithVersion(""))
+		if err != nil {
+			fmt.Fprintf(sess.Status, "  %s: %d remaining (cannot list: %v)\n", r.name, r.count, err)
+			continue
+		}
+		list, err := sess.Dynamic.Resource(gvr).Namespace(name).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			fmt.Fprintf(sess.Status, "  %s: %d remaining (cannot list: %v)\n", r.name, r.count, err)
+			continue
+		}
+		for _, obj := range list.Items {
+			finalizers := "none"
+			if len(obj.GetFinalizers()) > 0 {
+				finalizers = strings.Join(obj.GetFinalizers(), ", ")
+			}
+			fmt.Fprintf(sess.Status, "  %s/%s  finalizers: %s", r.name, obj.GetName(), finalizers)
+			if deleted := obj.GetDeletionTimestamp(); deleted != nil {
+				fmt.Fprintf(sess.Status, "  (deleting for %s)", time.Since(deleted.Time).Round(time.Second))
+			}
+			fmt.Fprintln(sess.Status)
+		}
+	}
+
+	fmt.Fprintln(sess.Status, "\n  Objects with finalizers stay until the controller that owns each finalizer")
+	fmt.Fprintln(sess.Status, "  removes it. Check that controller (e.g. KServe for LLMInferenceServices) is")
+	fmt.Fprintln(sess.Status, "  running before removing a finalizer by hand, which skips its cleanup.")
+	fmt.Fprintln(sess.Status)
+}
+
+// reportFinalized searches every namespaced resource type for the objects
+// that carry one of the remaining finalizers
+func reportFinalized(ctx context.Context, sess *session.Session, name string, held []remaining) {
+	wanted := make(map[string]bool)
+	for _, r := range held {
+		wanted[r.name] = true
+	}
+
+	// Discovery may fail for some API groups and still return the others
+	lists, err := sess.Discovery.ServerPreferredNamespacedResources()
+	if len(lists) == 0 && err != nil {
+		fmt.Fprintf(sess.Status, "  Cannot find the objects holding the finalizers: %v\n", err)
+		return
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			if !slices.Contains(res.Verbs, "list") {
+				continue
+			}
+			objects, err := sess.Dynamic.Resource(gv.WithResource(res.Name)).Namespace(name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				continue
+			}
+			for _, obj := range objects.Items {
+				for _, f := range obj.GetFinalizers() {
+					if wanted[f] {
+						fmt.Fprintf(sess.Status, "  %s/%s  finalizers: %s\n", res.Name, obj.GetName(), strings.Join(obj.GetFinalizers(), ", "))
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// pvcNames lists the persistent volume claims of a namespace
+func pvcNames(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+	list, err := sess.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, pvc := range list.Items {
+		names = append(names, pvc.Name)
+	}
+	return names, nil
+}
+
+// secretNames lists the secrets of a namespace
+func secretNames(ctx context.Context, sess *session.Session, namespace string) ([]string, error) {
+	list, err := sess.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, secret := range list.Items {
+		names = append(names, secret.Name)
+	}
+	return names, nil
+}
+
+// sample returns the first few names, e.g. " (a, b, c and 4 more)", or "" for none
+func sample(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	if len(names) <= inventoryNames {
+		return " (" + strings.Join(names, ", ") + ")"
+	}
+	return fmt.Sprintf(" (%s and %d more)", strings.Join(names[:inventoryNames], ", "), len(names)-inventoryNames)
+}
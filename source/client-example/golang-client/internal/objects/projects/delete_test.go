@@ -0,0 +1,123 @@
+package projects
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseRemaining(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		separator string
+		want      []remaining
+	}{
+		{
+			name:      "content",
+			message:   "Some resources are remaining: persistentvolumeclaims. has 1 resource instances, pods. has 2 resource instances",
+			separator: " has ",
+			want:      []remaining{{name: "persistentvolumeclaims.", count: 1}, {name: "pods.", count: 2}},
+		},
+		{
+			name:      "finalizers",
+			message:   "Some content in the namespace has finalizers remaining: kubernetes.io/pvc-protection in 1 resource instances",
+			separator: " in ",
+			want:      []remaining{{name: "kubernetes.io/pvc-protection", count: 1}},
+		},
+		{
+			name:      "several finalizers",
+			message:   "Some content in the namespace has finalizers remaining: a.example.com/f in 3 resource instances, b.example.com/g in 1 resource instances",
+			separator: " in ",
+			want:      []remaining{{name: "a.example.com/f", count: 3}, {name: "b.example.com/g", count: 1}},
+		},
+		{
+			name:      "no count",
+			message:   "Some resources are remaining: pods. has many resource instances",
+			separator: " has ",
+			want:      []remaining{{name: "pods.", count: 0}},
+		},
+		{
+			name:      "unparsable part is skipped",
+			message:   "Some resources are remaining: garbage, pods. has 2 resource instances",
+			separator: " has ",
+			want:      []remaining{{name: "pods.", count: 2}},
+		},
+		{
+			name:      "no colon",
+			message:   "All content successfully removed",
+			separator: " has ",
+		},
+		{
+			name:      "empty",
+			separator: " in ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRemaining(tt.message, tt.separator)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRemaining() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeRemaining(t *testing.T) {
+	content := corev1.NamespaceCondition{
+		Type:    corev1.NamespaceContentRemaining,
+		Status:  corev1.ConditionTrue,
+		Message: "Some resources are remaining: pods. has 2 resource instances",
+	}
+	finalizers := corev1.NamespaceCondition{
+		Type:    corev1.NamespaceFinalizersRemaining,
+		Status:  corev1.ConditionTrue,
+		Message: "Some content in the namespace has finalizers remaining: kubernetes.io/pvc-protection in 1 resource instances",
+	}
+	cleared := corev1.NamespaceCondition{
+		Type:    corev1.NamespaceContentRemaining,
+		Status:  corev1.ConditionFalse,
+		Message: "All content successfully removed",
+	}
+
+	tests := []struct {
+		name       string
+		conditions []corev1.NamespaceCondition
+		want       string
+	}{
+		{
+			name:       "content and finalizers",
+			conditions: []corev1.NamespaceCondition{content, finalizers},
+			want:       "remaining: 2 pods.; waiting on finalizers: kubernetes.io/pvc-protection (1)",
+		},
+		{
+			name:       "content only",
+			conditions: []corev1.NamespaceCondition{content},
+			want:       "remaining: 2 pods.",
+		},
+		{
+			name:       "finalizers only",
+			conditions: []corev1.NamespaceCondition{cleared, finalizers},
+			want:       "waiting on finalizers: kubernetes.io/pvc-protection (1)",
+		},
+		{
+			name:       "nothing reported",
+			conditions: []corev1.NamespaceCondition{cleared},
+			want:       "Terminating, removing content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := &corev1.Namespace{Status: corev1.NamespaceStatus{
+				Phase:      corev1.NamespaceTerminating,
+				Conditions: tt.conditions,
+			}}
+			if got := describeRemaining(namespace); got != tt.want {
+				t.Errorf("describeRemaining() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,82 +1,222 @@
 package projects
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/bryon/ocp-lister/internal/access"
 	"github.com/bryon/ocp-lister/internal/menu"
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// HandleCRUDMenu handles the CRUD menu for projects
-func HandleCRUDMenu(clientset *kubernetes.Clientset) {
-	crudMenu := menu.NewCRUDMenu("Projects")
-
-	for {
-		choice := crudMenu.DisplayAndGetChoice()
-
-		switch choice {
-		case "1": // List
-			if err := HandleList(clientset); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "2": // Get
-			name := menu.GetName("Enter project name: ")
-			if name == "" {
-				fmt.Println("Project name cannot be empty")
-				continue
-			}
-			if err := HandleGet(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "3": // Create
-			name := menu.GetName("Enter project name to create: ")
-			if name == "" {
-				fmt.Println("Project name cannot be empty")
-				continue
-			}
-			if err := HandleCreate(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "4": // Update
-			name := menu.GetName("Enter project name to update: ")
-			if name == "" {
-				fmt.Println("Project name cannot be empty")
-				continue
-			}
-			if err := HandleUpdate(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "5": // Delete
-			name := menu.GetName("Enter project name to delete: ")
-			if name == "" {
-				fmt.Println("Project name cannot be empty")
-				continue
-			}
-			// Get confirmation before deleting
-			if !menu.GetConfirmation(fmt.Sprintf("Are you sure you want to delete project '%s'", name)) {
-				fmt.Println("Deletion cancelled.")
-				continue
-			}
-			if err := HandleDelete(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "6": // Add Annotation
-			name := menu.GetName("Enter project name to annotate: ")
-			if name == "" {
-				fmt.Println("Project name cannot be empty")
-				continue
-			}
-			if err := HandleAddAnnotation(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-
-		case "B": // Back
-			return
-		}
+func init() {
+	menu.Register(menu.Item{Key: "A", Title: "Projects", Order: 10, Submenu: crudMenu})
+}
+
+// crudMenu lists the project actions
+var crudMenu = menu.NewMenu("Projects",
+	menu.Item{Key: "1", Title: "List (Read)", Action: handleList},
+	menu.Item{Key: "2", Title: "Get (Read by name)", Action: handleGet},
+	menu.Item{Key: "3", Title: "Create", Action: handleCreate, Permission: createPermission},
+	menu.Item{Key: "4", Title: "Update", Action: handleUpdate, Permission: func() access.Permission {
+		return updatePermission("")
+	}},
+	menu.Item{Key: "5", Title: "Delete", Action: handleDelete},
+	menu.Item{Key: "6", Title: "Add Annotation", Action: handleAddAnnotation, Permission: func() access.Permission {
+		return annotatePermission("")
+	}},
+	menu.Item{Key: "7", Title: "Provision tenant namespace", Action: handleProvisionTenant, Permission: provisionPermission},
+)
+
+// handleList lists projects as a table
+func handleList(state *menu.State) {
+	sess := state.Session
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleList(ctx, sess, output.Format{Kind: output.Table})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleGet shows a project as JSON
+func handleGet(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter project name", "project", projectNames(sess))
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleGet(ctx, sess, name, output.Format{Kind: output.JSON})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleCreate prompts for a name, display name and description and creates the project
+func handleCreate(state *menu.State) {
+	sess := state.Session
+	name := menu.GetName("Enter project name to create: ")
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+
+	opts := CreateOptions{
+		DisplayName: menu.GetName("Enter display name (optional): "),
+		Description: menu.GetName("Enter description (optional): "),
+	}
+	// Only cluster admins are offered the namespace fallback
+	var canCreateNamespaces bool
+	_ = sess.Run(func(ctx context.Context) error {
+		canCreateNamespaces = CanCreateNamespaces(ctx, sess)
+		return nil
+	})
+	if canCreateNamespaces {
+		opts.AsNamespace = menu.GetConfirmation("Create as a plain namespace, skipping the project template")
+	}
+
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleCreate(ctx, sess, name, opts)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleUpdate prompts for changes to a project, shows them and applies them after confirmation
+func handleUpdate(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter project name to update", "project", projectNames(sess))
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+
+	opts := UpdateOptions{
+		DisplayName: menu.GetName("Enter new display name (Enter to keep): "),
+		Description: menu.GetName("Enter new description (Enter to keep): "),
+		Labels:      strings.Fields(menu.GetName("Enter labels, e.g. team=acme old-label- (Enter for none): ")),
+		Annotations: strings.Fields(menu.GetName("Enter annotations, e.g. owner=acme old-note- (Enter for none): ")),
+	}
+
+	// Show the diff first
+	preview := opts
+	preview.DryRun = true
+	var changed bool
+	if err := sess.Run(func(ctx context.Context) (err error) {
+		changed, err = update(ctx, sess, name, preview)
+		return err
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if !changed {
+		return
+	}
+	if !menu.GetConfirmation("Apply these changes") {
+		fmt.Println("Update cancelled.")
+		return
+	}
+
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleUpdate(ctx, sess, name, opts)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleDelete shows what a project holds, deletes it after confirmation and optionally waits for it to go
+func handleDelete(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter project name to delete", "project", projectNames(sess))
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+
+	// Show the inventory before asking
+	if err := sess.Run(func(ctx context.Context) error {
+		return DescribeForDeletion(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// Get confirmation before deleting
+	if !menu.ConfirmName("project", name) {
+		fmt.Println("Deletion cancelled.")
+		return
+	}
+	opts := DeleteOptions{
+		Wait: menu.GetConfirmation(fmt.Sprintf("Wait up to %s for the deletion to finish", DefaultDeleteWaitTimeout)),
+	}
+
+	run := func(ctx context.Context) error {
+		return Delete(ctx, sess, name, opts)
+	}
+	var err error
+	if opts.Wait {
+		err = sess.RunFor(opts.OperationTimeout(), run)
+	} else {
+		err = sess.Run(run)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleAddAnnotation adds the test annotation to a project
+func handleAddAnnotation(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter project name to annotate", "project", projectNames(sess))
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleAddAnnotation(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// handleProvisionTenant prompts for a tenant's project, groups and quota and provisions it
+func handleProvisionTenant(state *menu.State) {
+	sess := state.Session
+	name := menu.GetName("Enter tenant namespace to provision, e.g. acme-inc-models: ")
+	if name == "" {
+		fmt.Println("Project name cannot be empty")
+		return
+	}
+
+	opts := TenantOptions{
+		CreateOptions: CreateOptions{
+			DisplayName: menu.GetName("Enter display name (optional): "),
+			Description: menu.GetName("Enter description (optional): "),
+		},
+		AdminGroup: menu.GetName("Enter group to bind as admin (Enter for none): "),
+		EditGroup:  menu.GetName("Enter group to bind as edit (Enter for none): "),
+		CPU:        menu.GetName(fmt.Sprintf("Enter CPU request quota (Enter for %s): ", DefaultTenantCPU)),
+		Memory:     menu.GetName(fmt.Sprintf("Enter memory request quota (Enter for %s): ", DefaultTenantMemory)),
+		GPUs:       menu.GetName(fmt.Sprintf("Enter GPU quota (Enter for %s): ", DefaultTenantGPUs)),
+	}
+
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleProvisionTenant(ctx, sess, name, opts)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// projectNames lists projects for menu.PickName
+func projectNames(sess *session.Session) func() ([]string, error) {
+	return func() (names []string, err error) {
+		err = sess.Run(func(ctx context.Context) error {
+			names, err = ListProjects(ctx, sess)
+			return err
+		})
+		return names, err
 	}
 }
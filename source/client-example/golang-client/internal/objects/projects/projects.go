@@ -2,90 +2,181 @@ package projects
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// ListProjects retrieves and returns a list of all projects (namespaces) the user has access to
-func ListProjects(clientset *kubernetes.Clientset) ([]string, error) {
-	ctx := context.Background()
+// getProjectResource returns the GVR for Project resources. Listing projects
+// instead of namespaces shows users only the projects they can access.
+func getProjectResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "project.openshift.io",
+		Version:  "v1",
+		Resource: "projects",
+	}
+}
+
+// getProjectRequestResource returns the GVR for ProjectRequest resources
+func getProjectRequestResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "project.openshift.io",
+		Version:  "v1",
+		Resource: "projectrequests",
+	}
+}
 
-	// List all namespaces (in OpenShift, projects are namespaces)
-	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+// ListProjects retrieves and returns a list of all projects the user has access to
+func ListProjects(ctx context.Context, sess *session.Session) ([]string, error) {
+	projectList, err := sess.Dynamic.Resource(getProjectResource()).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
 
-	// Extract namespace names
-	projects := make([]string, 0, len(namespaces.Items))
-	for _, ns := range namespaces.Items {
-		projects = append(projects, ns.Name)
+	// Extract project names
+	projects := make([]string, 0, len(projectList.Items))
+	for _, project := range projectList.Items {
+		projects = append(projects, project.GetName())
 	}
 
 	return projects, nil
 }
 
-// PrintProjects prints the list of projects to stdout
-func PrintProjects(projects []string) {
-	if len(projects) == 0 {
-		fmt.Println("No projects found.")
-		return
-	}
+// projectKind describes how projects are printed
+var projectKind = output.Kind{
+	Name: "project.project.openshift.io",
+	Columns: []output.Column{
+		{Header: "DISPLAY NAME", Value: func(obj map[string]interface{}) string {
+			return output.Annotation(obj, displayNameAnnotation)
+		}},
+		{Header: "STATUS", Value: func(obj map[string]interface{}) string { return output.Field(obj, "status", "phase") }},
+		{Header: "AGE", Value: output.Age},
+		{Header: "DESCRIPTION", Wide: true, Value: func(obj map[string]interface{}) string {
+			return output.Annotation(obj, descriptionAnnotation)
+		}},
+		{Header: "LABELS", Wide: true, Value: output.Labels},
+	},
+}
 
-	fmt.Printf("\nFound %d project(s):\n\n", len(projects))
-	for i, project := range projects {
-		fmt.Printf("%d. %s\n", i+1, project)
+// List returns the projects and how to print them
+func List(ctx context.Context, sess *session.Session) (output.Kind, []map[string]interface{}, error) {
+	projectList, err := sess.Dynamic.Resource(getProjectResource()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return projectKind, nil, fmt.Errorf("error listing projects: %w", err)
 	}
-	fmt.Println()
+
+	return projectKind, output.Items(projectList), nil
 }
 
 // HandleList handles the list action for projects
-func HandleList(clientset *kubernetes.Clientset) error {
-	projectList, err := ListProjects(clientset)
+func HandleList(ctx context.Context, sess *session.Session, format output.Format) error {
+	kind, items, err := List(ctx, sess)
 	if err != nil {
-		return fmt.Errorf("error listing projects: %w", err)
+		return err
 	}
-	PrintProjects(projectList)
-	return nil
+
+	return output.PrintList(sess.Out, format, kind, items)
 }
 
 // HandleGet handles the get action for a specific project
-func HandleGet(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
-
-	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+func HandleGet(ctx context.Context, sess *session.Session, name string, format output.Format) error {
+	project, err := sess.Dynamic.Resource(getProjectResource()).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("error getting project: %w", err)
 	}
 
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(namespace, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling project to JSON: %w", err)
-	}
+	return output.PrintObject(sess.Out, format, projectKind, project.Object)
+}
 
-	fmt.Println("\n" + string(jsonData))
-	fmt.Println()
+// createPermission is needed to request a project
+func createPermission() access.Permission {
+	return access.Permission{Verb: "create", Group: "project.openshift.io", Resource: "projectrequests"}
+}
 
-	return nil
+// namespacePermission is needed to create a project as a plain namespace
+func namespacePermission() access.Permission {
+	return access.Permission{Verb: "create", Resource: "namespaces"}
 }
 
-// HandleCreate handles the create action for projects
-func HandleCreate(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
+// annotatePermission is needed to annotate the named project, or any project when name is empty
+func annotatePermission(name string) access.Permission {
+	return access.Permission{Verb: "update", Resource: "namespaces", Name: name}
+}
 
+// CreateOptions describes a new project
+type CreateOptions struct {
+	DisplayName string
+	Description string
+	// AsNamespace creates a plain namespace instead of a ProjectRequest, skipping
+	// the cluster's project template. Only cluster admins may do this.
+	AsNamespace bool
+}
+
+// HandleCreate handles the create action for projects. The project is
+// requested with a ProjectRequest, so the cluster's project template applies
+// and the requester becomes its admin.
+func HandleCreate(ctx context.Context, sess *session.Session, name string, opts CreateOptions) error {
 	// Validate project name (Kubernetes namespace naming rules)
 	if err := validateProjectName(name); err != nil {
 		return fmt.Errorf("invalid project name: %w", err)
 	}
 
+	if opts.AsNamespace {
+		return createNamespace(ctx, sess, name, opts)
+	}
+
+	if err := access.Check(ctx, sess, createPermission()); err != nil {
+		return err
+	}
+
+	request := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "project.openshift.io/v1",
+			"kind":       "ProjectRequest",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	if opts.DisplayName != "" {
+		request.Object["displayName"] = opts.DisplayName
+	}
+	if opts.Description != "" {
+		request.Object["description"] = opts.Description
+	}
+
+	// The API server answers a ProjectRequest with the new Project
+	created, err := sess.Dynamic.Resource(getProjectRequestResource()).Create(ctx, request, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("project '%s' already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	phase, _, _ := unstructured.NestedString(created.Object, "status", "phase")
+	printCreated(sess, created.GetName(), phase, created.GetCreationTimestamp(), opts)
+
+	return nil
+}
+
+// createNamespace creates the project as a plain namespace carrying the
+// display name and description annotations
+func createNamespace(ctx context.Context, sess *session.Session, name string, opts CreateOptions) error {
+	if err := access.Check(ctx, sess, namespacePermission()); err != nil {
+		return fmt.Errorf("only cluster admins can create a project as a namespace: %w", err)
+	}
+
 	// Check if project already exists
-	_, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	_, err := sess.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
 	if err == nil {
 		return fmt.Errorf("project '%s' already exists", name)
 	}
@@ -97,23 +188,52 @@ func HandleCreate(clientset *kubernetes.Clientset, name string) error {
 			Labels: map[string]string{
 				"name": name,
 			},
+			Annotations: map[string]string{},
 		},
 	}
+	if opts.DisplayName != "" {
+		namespace.Annotations[displayNameAnnotation] = opts.DisplayName
+	}
+	if opts.Description != "" {
+		namespace.Annotations[descriptionAnnotation] = opts.Description
+	}
 
 	// Create the namespace
-	created, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	created, err := sess.Clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create project: %w", err)
 	}
 
-	fmt.Printf("\n✓ Successfully created project: %s\n", created.Name)
-	fmt.Printf("  Status: %s\n", created.Status.Phase)
-	fmt.Printf("  Created: %s\n", created.CreationTimestamp.Format("2006-01-02 15:04:05"))
-	fmt.Println()
+	printCreated(sess, created.Name, string(created.Status.Phase), created.CreationTimestamp, opts)
+	fmt.Fprintln(sess.Status, "  Note: created as a namespace; the project template and its RoleBindings were not applied.")
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
 
+// printCreated reports a newly created project
+func printCreated(sess *session.Session, name, phase string, created metav1.Time, opts CreateOptions) {
+	fmt.Fprintf(sess.Status, "\n✓ Successfully created project: %s\n", name)
+	if opts.DisplayName != "" {
+		fmt.Fprintf(sess.Status, "  Display name: %s\n", opts.DisplayName)
+	}
+	if opts.Description != "" {
+		fmt.Fprintf(sess.Status, "  Description: %s\n", opts.Description)
+	}
+	if phase != "" {
+		fmt.Fprintf(sess.Status, "  Status: %s\n", phase)
+	}
+	fmt.Fprintf(sess.Status, "  Created: %s\n", created.Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(sess.Status)
+}
+
+// CanCreateNamespaces reports whether the session's identity may create
+// projects as plain namespaces, i.e. is a cluster admin
+func CanCreateNamespaces(ctx context.Context, sess *session.Session) bool {
+	denied := access.Denied(ctx, sess, map[string]access.Permission{"namespace": namespacePermission()})
+	return len(denied) == 0
+}
+
 // validateProjectName validates a project name according to Kubernetes naming rules
 func validateProjectName(name string) error {
 	if name == "" {
@@ -149,49 +269,14 @@ func validateProjectName(name string) error {
 	return nil
 }
 
-// HandleUpdate handles the update action for projects (placeholder)
-func HandleUpdate(clientset *kubernetes.Clientset, name string) error {
-	fmt.Printf("Update project functionality not yet implemented for: %s\n", name)
-	return nil
-}
-
-// HandleDelete handles the delete action for projects
-func HandleDelete(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
-
-	// First, verify the project exists
-	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("error getting project: %w", err)
-	}
-
-	// Show project details before deletion
-	fmt.Printf("\nProject to delete: %s\n", namespace.Name)
-	fmt.Printf("Status: %s\n", namespace.Status.Phase)
-	fmt.Printf("Created: %s\n", namespace.CreationTimestamp.Format("2006-01-02 15:04:05"))
-	fmt.Println("\n⚠️  WARNING: This will delete the project and all resources within it!")
-	fmt.Println("   This action cannot be undone.")
-	fmt.Println()
-
-	// Delete the namespace
-	err = clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return fmt.Errorf("error deleting project: %w", err)
-	}
-
-	fmt.Printf("✓ Successfully initiated deletion of project: %s\n", name)
-	fmt.Println("  Note: Project deletion is asynchronous and may take some time to complete.")
-	fmt.Println()
-
-	return nil
-}
-
 // HandleAddAnnotation adds the annotation "bakerapps.net/test": "annotated" to a project
-func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
+func HandleAddAnnotation(ctx context.Context, sess *session.Session, name string) error {
+	if err := access.Check(ctx, sess, annotatePermission(name)); err != nil {
+		return err
+	}
 
 	// Get the existing namespace
-	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	namespace, err := sess.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("error getting project: %w", err)
 	}
@@ -205,14 +290,14 @@ func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
 	namespace.Annotations["bakerapps.net/test"] = "annotated"
 
 	// Update the namespace
-	updated, err := clientset.CoreV1().Namespaces().Update(ctx, namespace, metav1.UpdateOptions{})
+	updated, err := sess.Clientset.CoreV1().Namespaces().Update(ctx, namespace, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("error updating project with annotation: %w", err)
 	}
 
-	fmt.Printf("\n✓ Successfully added annotation to project: %s\n", updated.Name)
-	fmt.Printf("  Annotation: bakerapps.net/test = annotated\n")
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\n✓ Successfully added annotation to project: %s\n", updated.Name)
+	fmt.Fprintf(sess.Status, "  Annotation: bakerapps.net/test = annotated\n")
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
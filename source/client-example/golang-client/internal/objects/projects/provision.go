@@ -0,0 +1,258 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/session"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Default tenant quota, used when TenantOptions leaves a value empty
+const (
+	DefaultTenantCPU    = "16"
+	DefaultTenantMemory = "64Gi"
+	DefaultTenantGPUs   = "1"
+)
+
+// Names of the objects created in every tenant namespace
+const (
+	tenantQuotaName         = "tenant-quota"
+	tenantLimitRangeName    = "tenant-limits"
+	tenantNetworkPolicyName = "default-deny-except-maas-gateway"
+)
+
+// gatewayNameLabel is set by the Gateway API implementation on the gateway's pods
+const gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+
+// TenantOptions describes a tenant namespace
+type TenantOptions struct {
+	CreateOptions
+	// AdminGroup and EditGroup are bound to the admin and edit cluster roles in
+	// the namespace; at least one is required
+	AdminGroup string
+	EditGroup  string
+	// CPU, Memory and GPUs are the total requests allowed by the quota
+	CPU    string
+	Memory string
+	GPUs   string
+}
+
+// tenantObject is one object of the tenant bundle
+type tenantObject struct {
+	kind   string
+	name   string
+	create func(ctx context.Context, sess *session.Session) error
+}
+
+// provisionPermission is needed to provision tenant namespaces. Project admins
+// cannot create quotas, so provisioning is for cluster admins.
+func provisionPermission() access.Permission {
+	return access.Permission{Verb: "create", Resource: "resourcequotas"}
+}
+
+// tenantPermissions are needed to create the tenant objects in the namespace
+func tenantPermissions(namespace string) []access.Permission {
+	return []access.Permission{
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Namespace: namespace},
+		{Verb: "create", Resource: "resourcequotas", Namespace: namespace},
+		{Verb: "create", Resource: "limitranges", Namespace: namespace},
+		{Verb: "create", Group: "networking.k8s.io", Resource: "networkpolicies", Namespace: namespace},
+	}
+}
+
+// HandleProvisionTenant creates a project ready for a MaaS tenant: RoleBindings
+// for the tenant's groups, a ResourceQuota including GPUs, a LimitRange giving
+// containers default requests, and a NetworkPolicy that only admits the MaaS
+// gateway. Objects that already exist are left unchanged, so it can be run
+// again to complete a partly provisioned tenant.
+func HandleProvisionTenant(ctx context.Context, sess *session.Session, name string, opts TenantOptions) error {
+	if opts.AdminGroup == "" && opts.EditGroup == "" {
+		return fmt.Errorf("a tenant needs an admin or edit group")
+	}
+	if err := validateProjectName(name); err != nil {
+		return fmt.Errorf("invalid project name: %w", err)
+	}
+
+	// Check everything up front so a denied object does not leave half a tenant
+	for _, perm := range tenantPermissions(name) {
+		if err := access.Check(ctx, sess, perm); err != nil {
+			return err
+		}
+	}
+
+	objects, err := tenantObjects(sess, name, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Dynamic.Resource(getProjectResource()).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		fmt.Fprintf(sess.Status, "\n⚠️  Project %s already exists; adding any missing tenant objects\n", name)
+	case apierrors.IsNotFound(err):
+		if err := HandleCreate(ctx, sess, name, opts.CreateOptions); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("error getting project: %w", err)
+	}
+
+	fmt.Fprintf(sess.Status, "Provisioning tenant objects in %s:\n", name)
+	for _, obj := range objects {
+		err := obj.create(ctx, sess)
+		switch {
+		case apierrors.IsAlreadyExists(err):
+			fmt.Fprintf(sess.Status, "  ⚠️  %s %s already exists, left unchanged\n", obj.kind, obj.name)
+		case err != nil:
+			return fmt.Errorf("failed to create %s %s: %w", obj.kind, obj.name, err)
+		default:
+			fmt.Fprintf(sess.Status, "  ✓ %s %s\n", obj.kind, obj.name)
+		}
+	}
+
+	fmt.Fprintf(sess.Status, "\n✓ Tenant namespace %s is ready\n", name)
+	fmt.Fprintln(sess.Status)
+
+	return nil
+}
+
+// tenantObjects builds the objects of the tenant bundle for the namespace
+func tenantObjects(sess *session.Session, namespace string, opts TenantOptions) ([]tenantObject, error) {
+	quota, err := tenantQuota(namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []tenantObject
+	for _, binding := range []struct{ role, group string }{{"admin", opts.AdminGroup}, {"edit", opts.EditGroup}} {
+		if binding.group == "" {
+			continue
+		}
+		rb := groupRoleBinding(namespace, binding.role, binding.group)
+		objects = append(objects, tenantObject{kind: "RoleBinding", name: rb.Name, create: func(ctx context.Context, sess *session.Session) error {
+			_, err := sess.Clientset.RbacV1().RoleBindings(namespace).Create(ctx, rb, metav1.CreateOptions{})
+			return err
+		}})
+	}
+
+	limits := tenantLimitRange(namespace)
+	policy := gatewayOnlyNetworkPolicy(namespace, sess.Settings.GatewayName, sess.Settings.GatewayNamespace)
+
+	return append(objects,
+		tenantObject{kind: "ResourceQuota", name: quota.Name, create: func(ctx context.Context, sess *session.Session) error {
+			_, err := sess.Clientset.CoreV1().ResourceQuotas(namespace).Create(ctx, quota, metav1.CreateOptions{})
+			return err
+		}},
+		tenantObject{kind: "LimitRange", name: limits.Name, create: func(ctx context.Context, sess *session.Session) error {
+			_, err := sess.Clientset.CoreV1().LimitRanges(namespace).Create(ctx, limits, metav1.CreateOptions{})
+			return err
+		}},
+		tenantObject{kind: "NetworkPolicy", name: policy.Name, create: func(ctx context.Context, sess *session.Session) error {
+			_, err := sess.Clientset.NetworkingV1().NetworkPolicies(namespace).Create(ctx, policy, metav1.CreateOptions{})
+			return err
+		}},
+	), nil
+}
+
+// groupRoleBinding binds a group to a cluster role in the namespace
+func groupRoleBinding(namespace, role, group string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", group, role),
+			Namespace: namespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     role,
+		},
+		Subjects: []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.GroupKind,
+			Name:     group,
+		}},
+	}
+}
+
+// tenantQuota limits the total CPU, memory and GPU requests of the namespace
+func tenantQuota(namespace string, opts TenantOptions) (*corev1.ResourceQuota, error) {
+	hard := corev1.ResourceList{}
+	for _, limit := range []struct {
+		name  corev1.ResourceName
+		value string
+		def   string
+	}{
+		{corev1.ResourceRequestsCPU, opts.CPU, DefaultTenantCPU},
+		{corev1.ResourceRequestsMemory, opts.Memory, DefaultTenantMemory},
+		{corev1.ResourceName(corev1.DefaultResourceRequestsPrefix + "nvidia.com/gpu"), opts.GPUs, DefaultTenantGPUs},
+	} {
+		value := limit.value
+		if value == "" {
+			value = limit.def
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quota for %s %q: %w", limit.name, value, err)
+		}
+		hard[limit.name] = quantity
+	}
+
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantQuotaName, Namespace: namespace},
+		Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+	}, nil
+}
+
+// tenantLimitRange gives containers default requests and limits, which the
+// quota otherwise requires every pod to set
+func tenantLimitRange(namespace string) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantLimitRangeName, Namespace: namespace},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{{
+				Type: corev1.LimitTypeContainer,
+				DefaultRequest: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+				Default: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			}},
+		},
+	}
+}
+
+// gatewayOnlyNetworkPolicy denies all ingress except from the MaaS gateway's
+// pods and from pods in the namespace itself, which the model's scheduler and
+// replicas need to reach each other
+func gatewayOnlyNetworkPolicy(namespace, gatewayName, gatewayNamespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantNetworkPolicyName, Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{corev1.LabelMetadataName: gatewayNamespace},
+					},
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{gatewayNameLabel: gatewayName},
+					},
+				}}},
+				{From: []networkingv1.NetworkPolicyPeer{{
+					PodSelector: &metav1.LabelSelector{},
+				}}},
+			},
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/session"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Annotations holding a project's display metadata
+const (
+	displayNameAnnotation = "openshift.io/display-name"
+	descriptionAnnotation = "openshift.io/description"
+)
+
+// UpdateOptions describes changes to a project. Labels and annotations are
+// written as kubectl does: "key=value" sets a key and "key-" removes it.
+type UpdateOptions struct {
+	// DisplayName and Description are left unchanged when empty
+	DisplayName string
+	Description string
+	Labels      []string
+	Annotations []string
+	// DryRun shows the changes without applying them
+	DryRun bool
+}
+
+// updatePermission is needed to change the annotations, such as the display
+// name, of the named project. Project admins have it.
+func updatePermission(name string) access.Permission {
+	return access.Permission{Verb: "patch", Group: "project.openshift.io", Resource: "projects", Name: name}
+}
+
+// relabelPermission is needed to change the labels of the named project. The
+// Project API keeps labels read-only, so they are patched on the namespace.
+func relabelPermission(name string) access.Permission {
+	return access.Permission{Verb: "patch", Resource: "namespaces", Name: name}
+}
+
+// change is one added, changed or removed label or annotation
+type change struct {
+	field string // "label" or "annotation"
+	key   string
+	old   *string
+	new   *string
+}
+
+// HandleUpdate handles the update action for projects. It prints the changes
+// as a diff and applies them with a merge patch, so fields changed by others
+// in the meantime are kept.
+func HandleUpdate(ctx context.Context, sess *session.Session, name string, opts UpdateOptions) error {
+	_, err := update(ctx, sess, name, opts)
+	return err
+}
+
+// update applies the changes, or only shows them for a dry run, and reports
+// whether there were any
+func update(ctx context.Context, sess *session.Session, name string, opts UpdateOptions) (bool, error) {
+	labels, err := parseChanges("label", opts.Labels)
+	if err != nil {
+		return false, err
+	}
+	annotations, err := parseChanges("annotation", opts.Annotations)
+	if err != nil {
+		return false, err
+	}
+	if opts.DisplayName != "" {
+		annotations[displayNameAnnotation] = &opts.DisplayName
+	}
+	if opts.Description != "" {
+		annotations[descriptionAnnotation] = &opts.Description
+	}
+
+	project, err := sess.Dynamic.Resource(getProjectResource()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error getting project: %w", err)
+	}
+
+	labelChanges := diff("label", project.GetLabels(), labels)
+	annotationChanges := diff("annotation", project.GetAnnotations(), annotations)
+	if len(labelChanges) == 0 && len(annotationChanges) == 0 {
+		fmt.Fprintf(sess.Status, "No changes to project: %s\n", name)
+		return false, nil
+	}
+
+	// Only the permissions the changes need are checked, so a project admin
+	// can change the display metadata without rights on the namespace
+	for _, perm := range updatePermissions(name, labelChanges, annotationChanges) {
+		if err := access.Check(ctx, sess, perm); err != nil {
+			return false, err
+		}
+	}
+
+	fmt.Fprintf(sess.Status, "\nChanges to project %s:\n", name)
+	printDiff(sess, append(labelChanges, annotationChanges...))
+
+	if opts.DryRun {
+		fmt.Fprintln(sess.Status, "(dry run, nothing changed)")
+		fmt.Fprintln(sess.Status)
+		return true, nil
+	}
+
+	if len(annotationChanges) > 0 {
+		patch, err := mergePatch(annotationChanges)
+		if err != nil {
+			return false, err
+		}
+		if _, err := sess.Dynamic.Resource(getProjectResource()).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return false, fmt.Errorf("error updating project: %w", err)
+		}
+	}
+	if len(labelChanges) > 0 {
+		patch, err := mergePatch(labelChanges)
+		if err != nil {
+			return false, err
+		}
+		if _, err := sess.Clientset.CoreV1().Namespaces().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return false, fmt.Errorf("error updating project labels: %w", err)
+		}
+	}
+
+	fmt.Fprintf(sess.Status, "✓ Successfully updated project: %s\n", name)
+	fmt.Fprintln(sess.Status)
+
+	return true, nil
+}
+
+// updatePermissions returns the permissions needed to apply the changes
+func updatePermissions(name string, labelChanges, annotationChanges []change) []access.Permission {
+	var perms []access.Permission
+	if len(annotationChanges) > 0 {
+		perms = append(perms, updatePermission(name))
+	}
+	if len(labelChanges) > 0 {
+		perms = append(perms, relabelPermission(name))
+	}
+	return perms
+}
+
+// parseChanges reads "key=value" and "key-" arguments into the new value of
+// each key, nil for removed keys
+func parseChanges(field string, args []string) (map[string]*string, error) {
+	changes := make(map[string]*string)
+	for _, arg := range args {
+		var key string
+		var value *string
+		if i := strings.Index(arg, "="); i >= 0 {
+			key = arg[:i]
+			v := arg[i+1:]
+			value = &v
+		} else if strings.HasSuffix(arg, "-") {
+			key = strings.TrimSuffix(arg, "-")
+		} else {
+			return nil, fmt.Errorf("invalid %s %q: use key=value to set it or key- to remove it", field, arg)
+		}
+
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s key %q: %s", field, key, strings.Join(errs, "; "))
+		}
+		if field == "label" && value != nil {
+			if errs := validation.IsValidLabelValue(*value); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid label value %q: %s", *value, strings.Join(errs, "; "))
+			}
+		}
+		changes[key] = value
+	}
+	return changes, nil
+}
+
+// diff compares the current values with the requested ones and returns the
+// changes, sorted by key
+func diff(field string, current map[string]string, requested map[string]*string) []change {
+	var changes []change
+	for key, value := range requested {
+		old, exists := current[key]
+		switch {
+		case value == nil && exists:
+			changes = append(changes, change{field: field, key: key, old: &old})
+		case value != nil && !exists:
+			changes = append(changes, change{field: field, key: key, new: value})
+		case value != nil && old != *value:
+			changes = append(changes, change{field: field, key: key, old: &old, new: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].key < changes[j].key })
+	return changes
+}
+
+// printDiff shows removed values with "-" and new values with "+"
+func printDiff(sess *session.Session, changes []change) {
+	for _, c := range changes {
+		if c.old != nil {
+			fmt.Fprintf(sess.Status, "  - %s %s=%s\n", c.field, c.key, *c.old)
+		}
+		if c.new != nil {
+			fmt.Fprintf(sess.Status, "  + %s %s=%s\n", c.field, c.key, *c.new)
+		}
+	}
+	fmt.Fprintln(sess.Status)
+}
+
+// mergePatch builds a JSON merge patch of the changes; a null value removes a key
+func mergePatch(changes []change) ([]byte, error) {
+	fields := map[string]map[string]interface{}{}
+	for _, c := range changes {
+		name := c.field + "s"
+		if fields[name] == nil {
+			fields[name] = map[string]interface{}{}
+		}
+		if c.new != nil {
+			fields[name][c.key] = *c.new
+		} else {
+			fields[name][c.key] = nil
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"metadata": fields})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build patch: %w", err)
+	}
+	return patch, nil
+}
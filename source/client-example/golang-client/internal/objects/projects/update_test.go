@@ -0,0 +1,152 @@
+package projects
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bryon/ocp-lister/internal/access"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestParseChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		args    []string
+		want    map[string]*string
+		wantErr bool
+	}{
+		{name: "none", field: "label", want: map[string]*string{}},
+		{name: "set", field: "label", args: []string{"team=acme"}, want: map[string]*string{"team": ptr("acme")}},
+		{name: "set empty", field: "label", args: []string{"team="}, want: map[string]*string{"team": ptr("")}},
+		{name: "remove", field: "label", args: []string{"team-"}, want: map[string]*string{"team": nil}},
+		{name: "prefixed key", field: "label", args: []string{"bakerapps.net/tier=gold"}, want: map[string]*string{"bakerapps.net/tier": ptr("gold")}},
+		{name: "value with equals", field: "annotation", args: []string{"note=a=b"}, want: map[string]*string{"note": ptr("a=b")}},
+		{name: "annotation allows any value", field: "annotation", args: []string{"note=hello world!"}, want: map[string]*string{"note": ptr("hello world!")}},
+		{name: "later wins", field: "label", args: []string{"team=a", "team-"}, want: map[string]*string{"team": nil}},
+		{name: "several", field: "label", args: []string{"a=1", "b-"}, want: map[string]*string{"a": ptr("1"), "b": nil}},
+		{name: "missing operator", field: "label", args: []string{"team"}, wantErr: true},
+		{name: "empty key", field: "label", args: []string{"=acme"}, wantErr: true},
+		{name: "invalid key", field: "annotation", args: []string{"bad key=x"}, wantErr: true},
+		{name: "invalid label value", field: "label", args: []string{"team=hello world"}, wantErr: true},
+		{name: "label value too long", field: "label", args: []string{"team=" + strings.Repeat("a", 64)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChanges(tt.field, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseChanges(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseChanges(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []change
+		want    string
+	}{
+		{
+			name: "set label",
+			changes: []change{
+				{field: "label", key: "team", new: ptr("acme")},
+			},
+			want: `{"metadata":{"labels":{"team":"acme"}}}`,
+		},
+		{
+			name: "remove label",
+			changes: []change{
+				{field: "label", key: "team", old: ptr("acme")},
+			},
+			want: `{"metadata":{"labels":{"team":null}}}`,
+		},
+		{
+			name: "labels and annotations",
+			changes: []change{
+				{field: "label", key: "team", old: ptr("a"), new: ptr("b")},
+				{field: "annotation", key: "openshift.io/display-name", new: ptr("Acme")},
+				{field: "annotation", key: "note", old: ptr("x")},
+			},
+			want: `{"metadata":{"annotations":{"note":null,"openshift.io/display-name":"Acme"},"labels":{"team":"b"}}}`,
+		},
+		{
+			name: "no changes",
+			want: `{"metadata":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergePatch(tt.changes)
+			if err != nil {
+				t.Fatalf("mergePatch() error = %v", err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatalf("mergePatch() returned invalid JSON %s: %v", got, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("mergePatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := map[string]string{"team": "acme", "env": "prod", "app": "web"}
+	requested := map[string]*string{
+		"team":  ptr("other"), // changed
+		"env":   ptr("prod"),  // unchanged
+		"tier":  ptr("gold"),  // added
+		"app":   nil,          // removed
+		"owner": nil,          // already absent
+	}
+
+	got := diff("label", current, requested)
+	want := []change{
+		{field: "label", key: "app", old: ptr("web")},
+		{field: "label", key: "team", old: ptr("acme"), new: ptr("other")},
+		{field: "label", key: "tier", new: ptr("gold")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdatePermissions(t *testing.T) {
+	labels := []change{{field: "label", key: "team", new: ptr("acme")}}
+	annotations := []change{{field: "annotation", key: displayNameAnnotation, new: ptr("Acme")}}
+
+	tests := []struct {
+		name        string
+		labels      []change
+		annotations []change
+		want        []access.Permission
+	}{
+		{name: "annotations only", annotations: annotations, want: []access.Permission{updatePermission("acme")}},
+		{name: "labels only", labels: labels, want: []access.Permission{relabelPermission("acme")}},
+		{name: "both", labels: labels, annotations: annotations, want: []access.Permission{updatePermission("acme"), relabelPermission("acme")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updatePermissions("acme", tt.labels, tt.annotations); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updatePermissions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if got := updatePermission("acme"); got.Group != "project.openshift.io" || got.Resource != "projects" {
+		t.Errorf("updatePermission() = %+v, want a patch of projects.project.openshift.io", got)
+	}
+}
@@ -0,0 +1,118 @@
+package tiers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// The ConfigMap the MaaS API reads its tiers from
+// (components/platform/maas/base/tier-to-group-mapping.yaml)
+const (
+	MappingNamespace = "maas-api"
+	MappingName      = "tier-to-group-mapping"
+)
+
+// Tier is one entry of the tier-to-group mapping
+type Tier struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Level       int      `json:"level"`
+	Groups      []string `json:"groups"`
+}
+
+// ListTiers reads the tiers from the tier-to-group-mapping ConfigMap
+func ListTiers(ctx context.Context, sess *session.Session) ([]Tier, error) {
+	configMap, err := sess.Clientset.CoreV1().ConfigMaps(MappingNamespace).Get(ctx, MappingName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s: %w", MappingNamespace, MappingName, err)
+	}
+
+	var tiers []Tier
+	if err := yaml.Unmarshal([]byte(configMap.Data["tiers"]), &tiers); err != nil {
+		return nil, fmt.Errorf("failed to parse tiers in %s/%s: %w", MappingNamespace, MappingName, err)
+	}
+
+	return tiers, nil
+}
+
+// Resolve returns the tier a user with the given groups is assigned, and the
+// group that grants it. Like the MaaS API, the matching tier with the highest
+// level wins. It returns nil when no tier matches.
+func Resolve(tiers []Tier, groups []string) (*Tier, string) {
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[group] = true
+	}
+
+	var best *Tier
+	var via string
+	for i := range tiers {
+		for _, group := range tiers[i].Groups {
+			if member[group] && (best == nil || tiers[i].Level > best.Level) {
+				best, via = &tiers[i], group
+				break
+			}
+		}
+	}
+
+	return best, via
+}
+
+// ForGroup returns the names of the tiers a group is mapped to
+func ForGroup(tiers []Tier, group string) []string {
+	var names []string
+	for _, tier := range tiers {
+		for _, member := range tier.Groups {
+			if member == group {
+				names = append(names, tier.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// tierKind describes how tiers are printed
+var tierKind = output.Kind{
+	Name: "tier",
+	Columns: []output.Column{
+		{Header: "LEVEL", Value: func(obj map[string]interface{}) string { return output.Field(obj, "level") }},
+		{Header: "GROUPS", Value: func(obj map[string]interface{}) string { return output.StringSlice(obj, "groups") }},
+		{Header: "DESCRIPTION", Wide: true, Value: func(obj map[string]interface{}) string { return output.Field(obj, "description") }},
+	},
+	ObjectName: func(obj map[string]interface{}) string { return output.Field(obj, "name") },
+}
+
+// List returns the MaaS tiers and how to print them
+func List(ctx context.Context, sess *session.Session) (output.Kind, []map[string]interface{}, error) {
+	tiers, err := ListTiers(ctx, sess)
+	if err != nil {
+		return tierKind, nil, err
+	}
+
+	items := make([]map[string]interface{}, 0, len(tiers))
+	for _, tier := range tiers {
+		item, err := output.ToMap(tier)
+		if err != nil {
+			return tierKind, nil, err
+		}
+		items = append(items, item)
+	}
+
+	return tierKind, items, nil
+}
+
+// HandleList lists the MaaS tiers and the groups mapped to them
+func HandleList(ctx context.Context, sess *session.Session, format output.Format) error {
+	kind, items, err := List(ctx, sess)
+	if err != nil {
+		return err
+	}
+
+	return output.PrintList(sess.Out, format, kind, items)
+}
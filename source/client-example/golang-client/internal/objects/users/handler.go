@@ -1,82 +1,138 @@
 package users
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/bryon/ocp-lister/internal/access"
 	"github.com/bryon/ocp-lister/internal/menu"
-	"k8s.io/client-go/kubernetes"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
 )
 
-// HandleCRUDMenu handles the CRUD menu for users
-func HandleCRUDMenu(clientset *kubernetes.Clientset) {
-	crudMenu := menu.NewCRUDMenu("Users")
+func init() {
+	menu.Register(menu.Item{Key: "C", Title: "Users", Order: 30, Submenu: crudMenu})
+}
 
-	for {
-		choice := crudMenu.DisplayAndGetChoice()
+// crudMenu lists the user actions
+var crudMenu = menu.NewMenu("Users",
+	menu.Item{Key: "1", Title: "List (Read)", Action: handleList},
+	menu.Item{Key: "2", Title: "Get (Read by name)", Action: handleGet},
+	menu.Item{Key: "3", Title: "Create", Action: handleCreate},
+	menu.Item{Key: "4", Title: "Update", Action: handleUpdate},
+	menu.Item{Key: "5", Title: "Delete", Action: handleDelete, Permission: func() access.Permission {
+		return deletePermission("")
+	}},
+	menu.Item{Key: "6", Title: "Add Annotation", Action: handleAddAnnotation, Permission: func() access.Permission {
+		return annotatePermission("")
+	}},
+)
 
-		switch choice {
-		case "1": // List
-			if err := HandleList(clientset); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleList lists users as a table
+func handleList(state *menu.State) {
+	sess := state.Session
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleList(ctx, sess, output.Format{Kind: output.Table})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "2": // Get
-			name := menu.GetName("Enter user name: ")
-			if name == "" {
-				fmt.Println("User name cannot be empty")
-				continue
-			}
-			if err := HandleGet(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleGet shows a user as JSON
+func handleGet(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter user name", "user", userNames(sess))
+	if name == "" {
+		fmt.Println("User name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleGet(ctx, sess, name, output.Format{Kind: output.JSON})
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "3": // Create
-			name := menu.GetName("Enter user name to create: ")
-			if name == "" {
-				fmt.Println("User name cannot be empty")
-				continue
-			}
-			if err := HandleCreate(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleCreate prompts for a name and creates the user
+func handleCreate(state *menu.State) {
+	sess := state.Session
+	name := menu.GetName("Enter user name to create: ")
+	if name == "" {
+		fmt.Println("User name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleCreate(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "4": // Update
-			name := menu.GetName("Enter user name to update: ")
-			if name == "" {
-				fmt.Println("User name cannot be empty")
-				continue
-			}
-			if err := HandleUpdate(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleUpdate updates a user
+func handleUpdate(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter user name to update", "user", userNames(sess))
+	if name == "" {
+		fmt.Println("User name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleUpdate(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "5": // Delete
-			name := menu.GetName("Enter user name to delete: ")
-			if name == "" {
-				fmt.Println("User name cannot be empty")
-				continue
-			}
-			// Get confirmation before deleting
-			if !menu.GetConfirmation(fmt.Sprintf("Are you sure you want to delete user '%s'", name)) {
-				fmt.Println("Deletion cancelled.")
-				continue
-			}
-			if err := HandleDelete(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleDelete deletes a user after confirmation
+func handleDelete(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter user name to delete", "user", userNames(sess))
+	if name == "" {
+		fmt.Println("User name cannot be empty")
+		return
+	}
+	// Refuse protected users before asking
+	if err := sess.Run(func(ctx context.Context) error {
+		_, err := deletableUser(ctx, sess, name)
+		return err
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// Get confirmation before deleting
+	if !menu.ConfirmName("user", name) {
+		fmt.Println("Deletion cancelled.")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleDelete(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "6": // Add Annotation
-			name := menu.GetName("Enter user name to annotate: ")
-			if name == "" {
-				fmt.Println("User name cannot be empty")
-				continue
-			}
-			if err := HandleAddAnnotation(clientset, name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+// handleAddAnnotation adds the test annotation to a user
+func handleAddAnnotation(state *menu.State) {
+	sess := state.Session
+	name := menu.PickName("Enter user name to annotate", "user", userNames(sess))
+	if name == "" {
+		fmt.Println("User name cannot be empty")
+		return
+	}
+	if err := sess.Run(func(ctx context.Context) error {
+		return HandleAddAnnotation(ctx, sess, name)
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
 
-		case "B": // Back
-			return
-		}
+// userNames lists users for menu.PickName
+func userNames(sess *session.Session) func() ([]string, error) {
+	return func() (names []string, err error) {
+		err = sess.Run(func(ctx context.Context) error {
+			names, err = ListUsers(ctx, sess)
+			return err
+		})
+		return names, err
 	}
 }
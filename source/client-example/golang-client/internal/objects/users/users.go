@@ -2,41 +2,17 @@ package users
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
-	"github.com/bryon/ocp-lister/internal/auth"
-	"github.com/bryon/ocp-lister/internal/client"
+	"github.com/bryon/ocp-lister/internal/access"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/protect"
+	"github.com/bryon/ocp-lister/internal/session"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
 )
 
-// getUserClient creates a dynamic client for User resources
-func getUserClient(clientset *kubernetes.Clientset) (dynamic.Interface, error) {
-	// Get auth config to retrieve server, username, password
-	authConfig, err := auth.LoadFromEnv()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load auth config: %w", err)
-	}
-
-	// Get REST config
-	config, err := client.GetRESTConfig(authConfig.Server, authConfig.Username, authConfig.Password)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get REST config: %w", err)
-	}
-
-	// Create dynamic client
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
-	}
-
-	return dynamicClient, nil
-}
-
 // getUserResource returns the GVR for User resources
 func getUserResource() schema.GroupVersionResource {
 	return schema.GroupVersionResource{
@@ -47,16 +23,9 @@ func getUserResource() schema.GroupVersionResource {
 }
 
 // ListUsers retrieves and returns a list of all users
-func ListUsers(clientset *kubernetes.Clientset) ([]string, error) {
-	ctx := context.Background()
-
-	dynamicClient, err := getUserClient(clientset)
-	if err != nil {
-		return nil, err
-	}
-
+func ListUsers(ctx context.Context, sess *session.Session) ([]string, error) {
 	// List users
-	userList, err := dynamicClient.Resource(getUserResource()).List(ctx, metav1.ListOptions{})
+	userList, err := sess.Dynamic.Resource(getUserResource()).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -72,68 +41,51 @@ func ListUsers(clientset *kubernetes.Clientset) ([]string, error) {
 	return users, nil
 }
 
-// PrintUsers prints the list of users to stdout
-func PrintUsers(users []string) {
-	if len(users) == 0 {
-		fmt.Println("No users found.")
-		return
-	}
-
-	fmt.Printf("\nFound %d user(s):\n\n", len(users))
-	for i, user := range users {
-		fmt.Printf("%d. %s\n", i+1, user)
-	}
-	fmt.Println()
+// userKind describes how users are printed
+var userKind = output.Kind{
+	Name: "user.user.openshift.io",
+	Columns: []output.Column{
+		{Header: "FULL NAME", Value: func(obj map[string]interface{}) string { return output.Field(obj, "fullName") }},
+		{Header: "IDENTITIES", Value: func(obj map[string]interface{}) string { return output.StringSlice(obj, "identities") }},
+		{Header: "AGE", Value: output.Age},
+		{Header: "LABELS", Wide: true, Value: output.Labels},
+	},
 }
 
-// HandleList handles the list action for users
-func HandleList(clientset *kubernetes.Clientset) error {
-	userList, err := ListUsers(clientset)
+// List returns the users and how to print them
+func List(ctx context.Context, sess *session.Session) (output.Kind, []map[string]interface{}, error) {
+	userList, err := sess.Dynamic.Resource(getUserResource()).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("error listing users: %w", err)
+		return userKind, nil, fmt.Errorf("error listing users: %w", err)
 	}
-	PrintUsers(userList)
-	return nil
-}
 
-// HandleGet handles the get action for a specific user
-func HandleGet(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
+	return userKind, output.Items(userList), nil
+}
 
-	dynamicClient, err := getUserClient(clientset)
+// HandleList handles the list action for users
+func HandleList(ctx context.Context, sess *session.Session, format output.Format) error {
+	kind, items, err := List(ctx, sess)
 	if err != nil {
 		return err
 	}
 
-	// Get user
-	user, err := dynamicClient.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("error getting user: %w", err)
-	}
+	return output.PrintList(sess.Out, format, kind, items)
+}
 
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(user.Object, "", "  ")
+// HandleGet handles the get action for a specific user
+func HandleGet(ctx context.Context, sess *session.Session, name string, format output.Format) error {
+	user, err := sess.Dynamic.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("error marshaling user to JSON: %w", err)
+		return fmt.Errorf("error getting user: %w", err)
 	}
 
-	fmt.Println("\n" + string(jsonData))
-	fmt.Println()
-
-	return nil
+	return output.PrintObject(sess.Out, format, userKind, user.Object)
 }
 
 // HandleCreate handles the create action for users
-func HandleCreate(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
-
-	dynamicClient, err := getUserClient(clientset)
-	if err != nil {
-		return err
-	}
-
+func HandleCreate(ctx context.Context, sess *session.Session, name string) error {
 	// Check if user already exists
-	_, err = dynamicClient.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
+	_, err := sess.Dynamic.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
 	if err == nil {
 		return fmt.Errorf("user '%s' already exists", name)
 	}
@@ -150,74 +102,90 @@ func HandleCreate(clientset *kubernetes.Clientset, name string) error {
 	}
 
 	// Create the user
-	created, err := dynamicClient.Resource(getUserResource()).Create(ctx, user, metav1.CreateOptions{})
+	created, err := sess.Dynamic.Resource(getUserResource()).Create(ctx, user, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
 	createdName, _, _ := unstructured.NestedString(created.Object, "metadata", "name")
-	fmt.Printf("\n✓ Successfully created user: %s\n", createdName)
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\n✓ Successfully created user: %s\n", createdName)
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
 
-// HandleUpdate handles the update action for users (placeholder)
-func HandleUpdate(clientset *kubernetes.Clientset, name string) error {
-	fmt.Printf("Update user functionality not yet implemented for: %s\n", name)
-	return nil
+// HandleUpdate handles the update action for users (placeholder). It fails,
+// so nothing mistakes the missing update for a successful one.
+func HandleUpdate(ctx context.Context, sess *session.Session, name string) error {
+	return fmt.Errorf("updating user %s is not implemented yet", name)
 }
 
-// HandleDelete handles the delete action for users
-func HandleDelete(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
+// deletePermission is needed to delete the named user, or any user when name is empty
+func deletePermission(name string) access.Permission {
+	return access.Permission{Verb: "delete", Group: "user.openshift.io", Resource: "users", Name: name}
+}
+
+// annotatePermission is needed to annotate the named user, or any user when name is empty
+func annotatePermission(name string) access.Permission {
+	return access.Permission{Verb: "update", Group: "user.openshift.io", Resource: "users", Name: name}
+}
 
-	dynamicClient, err := getUserClient(clientset)
+// deletableUser gets a user, refusing users labelled as protected
+func deletableUser(ctx context.Context, sess *session.Session, name string) (*unstructured.Unstructured, error) {
+	user, err := sess.Dynamic.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+	if err := protect.Check("user", user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// HandleDelete handles the delete action for users
+func HandleDelete(ctx context.Context, sess *session.Session, name string) error {
+	if err := access.Check(ctx, sess, deletePermission(name)); err != nil {
 		return err
 	}
 
 	// Get user first to show details
-	user, err := dynamicClient.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
+	user, err := deletableUser(ctx, sess, name)
 	if err != nil {
-		return fmt.Errorf("error getting user: %w", err)
+		return err
 	}
 
 	userName, _, _ := unstructured.NestedString(user.Object, "metadata", "name")
 	created, _, _ := unstructured.NestedString(user.Object, "metadata", "creationTimestamp")
 
 	// Show user details before deletion
-	fmt.Printf("\nUser to delete: %s\n", userName)
+	fmt.Fprintf(sess.Status, "\nUser to delete: %s\n", userName)
 	if created != "" {
-		fmt.Printf("Created: %s\n", created)
+		fmt.Fprintf(sess.Status, "Created: %s\n", created)
 	}
-	fmt.Println("\n⚠️  WARNING: This will delete the user!")
-	fmt.Println("   This action cannot be undone.")
-	fmt.Println()
+	fmt.Fprintln(sess.Status, "\n⚠️  WARNING: This will delete the user!")
+	fmt.Fprintln(sess.Status, "   This action cannot be undone.")
+	fmt.Fprintln(sess.Status)
 
 	// Delete the user
-	err = dynamicClient.Resource(getUserResource()).Delete(ctx, name, metav1.DeleteOptions{})
+	err = sess.Dynamic.Resource(getUserResource()).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("error deleting user: %w", err)
 	}
 
-	fmt.Printf("✓ Successfully deleted user: %s\n", name)
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "✓ Successfully deleted user: %s\n", name)
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
 
 // HandleAddAnnotation adds the annotation "bakerapps.net/test": "annotated" to a user
-func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
-	ctx := context.Background()
-
-	dynamicClient, err := getUserClient(clientset)
-	if err != nil {
+func HandleAddAnnotation(ctx context.Context, sess *session.Session, name string) error {
+	if err := access.Check(ctx, sess, annotatePermission(name)); err != nil {
 		return err
 	}
 
 	// Get the existing user
-	user, err := dynamicClient.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
+	user, err := sess.Dynamic.Resource(getUserResource()).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("error getting user: %w", err)
 	}
@@ -240,15 +208,15 @@ func HandleAddAnnotation(clientset *kubernetes.Clientset, name string) error {
 	}
 
 	// Update the user
-	updated, err := dynamicClient.Resource(getUserResource()).Update(ctx, user, metav1.UpdateOptions{})
+	updated, err := sess.Dynamic.Resource(getUserResource()).Update(ctx, user, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("error updating user with annotation: %w", err)
 	}
 
 	updatedName, _, _ := unstructured.NestedString(updated.Object, "metadata", "name")
-	fmt.Printf("\n✓ Successfully added annotation to user: %s\n", updatedName)
-	fmt.Printf("  Annotation: bakerapps.net/test = annotated\n")
-	fmt.Println()
+	fmt.Fprintf(sess.Status, "\n✓ Successfully added annotation to user: %s\n", updatedName)
+	fmt.Fprintf(sess.Status, "  Annotation: bakerapps.net/test = annotated\n")
+	fmt.Fprintln(sess.Status)
 
 	return nil
 }
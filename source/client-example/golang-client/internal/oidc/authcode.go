@@ -0,0 +1,164 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// loginTimeout bounds how long we wait for the user to finish logging in
+const loginTimeout = 5 * time.Minute
+
+// callbackPath is the path of the loopback redirect URI
+const callbackPath = "/callback"
+
+// callbackResult carries the outcome of the browser redirect
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// LoginWithBrowser performs the authorization code flow with PKCE (RFC 7636).
+// A loopback listener on 127.0.0.1 receives the redirect, as recommended for
+// native apps by RFC 8252, so no client secret has to be embedded in the tool.
+func LoginWithBrowser(ctx context.Context, cfg *Config) (*Tokens, error) {
+	metadata, err := discover(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.AuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("OIDC provider %s does not advertise an authorization endpoint", cfg.IssuerURL)
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(cfg.CallbackPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start loopback listener: %w", err)
+	}
+	defer listener.Close()
+
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), callbackPath)
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization endpoint %q: %w", metadata.AuthorizationEndpoint, err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", cfg.scopes())
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	results := make(chan callbackResult, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != callbackPath {
+				http.NotFound(w, r)
+				return
+			}
+			result := parseCallback(r, state)
+			if result.err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "<html><body><h2>Login failed</h2><p>%s</p></body></html>", html.EscapeString(result.err.Error()))
+			} else {
+				fmt.Fprint(w, "<html><body><h2>Login successful</h2><p>You can close this window and return to the terminal.</p></body></html>")
+			}
+			select {
+			case results <- result:
+			default:
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintln(os.Stderr, "\nOpen the following URL in your browser to log in:")
+	fmt.Fprintf(os.Stderr, "\n  %s\n\n", authURL.String())
+	if err := openBrowser(authURL.String()); err == nil {
+		fmt.Fprintln(os.Stderr, "(A browser window has been opened for you.)")
+	}
+	fmt.Fprintln(os.Stderr, "Waiting for login to complete...")
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for browser login")
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", result.code)
+	data.Set("redirect_uri", redirectURI)
+	data.Set("code_verifier", verifier)
+
+	return requestToken(ctx, cfg, metadata.TokenEndpoint, data)
+}
+
+// parseCallback validates the redirect and extracts the authorization code
+func parseCallback(r *http.Request, state string) callbackResult {
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return callbackResult{err: &Error{Code: errCode, Description: query.Get("error_description")}}
+	}
+	if query.Get("state") != state {
+		return callbackResult{err: fmt.Errorf("state mismatch in login callback")}
+	}
+	code := query.Get("code")
+	if code == "" {
+		return callbackResult{err: fmt.Errorf("no authorization code in login callback")}
+	}
+	return callbackResult{code: code}
+}
+
+// randomString returns n random bytes encoded as unpadded base64url
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// openBrowser tries to open the URL in the user's default browser
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// deviceAuthorization represents the device authorization response (RFC 8628)
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// LoginWithDeviceCode performs the device authorization grant (RFC 8628).
+// It suits headless machines: the user completes the login on any other device.
+func LoginWithDeviceCode(ctx context.Context, cfg *Config) (*Tokens, error) {
+	metadata, err := discover(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("OIDC provider %s does not support the device flow (enable \"OAuth 2.0 Device Authorization Grant\" on the client)", cfg.IssuerURL)
+	}
+
+	device, err := requestDeviceCode(ctx, cfg, metadata.DeviceAuthorizationEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(os.Stderr, "\nTo log in, visit:")
+	fmt.Fprintf(os.Stderr, "\n  %s\n\n", device.VerificationURI)
+	fmt.Fprintf(os.Stderr, "and enter the code: %s\n", device.UserCode)
+	if device.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "\nOr open this URL directly:\n\n  %s\n", device.VerificationURIComplete)
+	}
+	fmt.Fprintln(os.Stderr, "\nWaiting for login to complete...")
+
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(device.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = loginTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, expiresIn)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("device code expired before login completed")
+		case <-time.After(interval):
+		}
+
+		data := url.Values{}
+		data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+		data.Set("device_code", device.DeviceCode)
+
+		tokens, err := requestToken(ctx, cfg, metadata.TokenEndpoint, data)
+		if err == nil {
+			return tokens, nil
+		}
+
+		var oauthErr *Error
+		if !errors.As(err, &oauthErr) {
+			return nil, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+			// keep polling
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, fmt.Errorf("login was denied")
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired before login completed")
+		default:
+			return nil, err
+		}
+	}
+}
+
+// requestDeviceCode starts the device flow
+func requestDeviceCode(ctx context.Context, cfg *Config, endpoint string) (*deviceAuthorization, error) {
+	data := url.Values{}
+	data.Set("client_id", cfg.ClientID)
+	data.Set("scope", cfg.scopes())
+	if cfg.ClientSecret != "" {
+		data.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device authorization request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("device authorization failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var device deviceAuthorization
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return nil, fmt.Errorf("failed to decode device authorization response: %w", err)
+	}
+	if device.DeviceCode == "" || device.UserCode == "" {
+		return nil, fmt.Errorf("device authorization response is missing the device or user code")
+	}
+
+	return &device, nil
+}
@@ -0,0 +1,259 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultScopes are requested when no scopes are configured
+var DefaultScopes = []string{"openid", "profile", "email", "groups", "offline_access"}
+
+// Config holds the settings for an OpenID Connect provider such as Keycloak
+type Config struct {
+	// IssuerURL is the realm URL, e.g. https://keycloak.apps.example.com/realms/maas-tenants
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// CallbackPort is the loopback port for the browser flow (0 picks a free port)
+	CallbackPort int
+	// HTTPClient is used for all requests to the provider
+	HTTPClient *http.Client
+}
+
+// Tokens holds the tokens issued by the provider
+type Tokens struct {
+	AccessToken  string
+	IDToken      string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// BearerToken returns the token to present to the cluster and the MaaS gateway.
+// The ID token is preferred because the Kubernetes API server validates its audience
+// against the client ID; both are JWTs signed by the realm, so the gateway accepts either.
+func (t *Tokens) BearerToken() string {
+	if t.IDToken != "" {
+		return t.IDToken
+	}
+	return t.AccessToken
+}
+
+// providerMetadata holds the endpoints advertised by the provider
+type providerMetadata struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+}
+
+// tokenResponse represents a token endpoint response
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// scopes returns the configured scopes or the defaults
+func (c *Config) scopes() string {
+	if len(c.Scopes) == 0 {
+		return strings.Join(DefaultScopes, " ")
+	}
+	return strings.Join(c.Scopes, " ")
+}
+
+// httpClient returns the configured HTTP client or the default one
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// discover fetches the provider's OpenID configuration
+func discover(ctx context.Context, cfg *Config) (*providerMetadata, error) {
+	metadataURL := strings.TrimSuffix(cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: could not reach %s: %w", metadataURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OIDC discovery failed: %s returned status %d: %s", metadataURL, resp.StatusCode, string(body))
+	}
+
+	var metadata providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: invalid metadata from %s: %w", metadataURL, err)
+	}
+	if metadata.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery failed: %s did not advertise a token endpoint", metadataURL)
+	}
+
+	return &metadata, nil
+}
+
+// requestToken posts a form to the token endpoint and decodes the response.
+// Provider errors are returned as *Error so callers can react to codes like authorization_pending.
+func requestToken(ctx context.Context, cfg *Config, tokenURL string, data url.Values) (*Tokens, error) {
+	data.Set("client_id", cfg.ClientID)
+	if cfg.ClientSecret != "" {
+		data.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tokenResp tokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if tokenResp.Error != "" {
+		return nil, &Error{Code: tokenResp.Error, Description: tokenResp.ErrorDescription}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	tokens := &Tokens{
+		AccessToken:  tokenResp.AccessToken,
+		IDToken:      tokenResp.IDToken,
+		RefreshToken: tokenResp.RefreshToken,
+	}
+	if exp, ok := JWTExpiry(tokens.BearerToken()); ok {
+		tokens.ExpiresAt = exp
+	} else if tokenResp.ExpiresIn > 0 {
+		tokens.ExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for new tokens
+func Refresh(ctx context.Context, cfg *Config, refreshToken string) (*Tokens, error) {
+	metadata, err := discover(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("scope", cfg.scopes())
+
+	return requestToken(ctx, cfg, metadata.TokenEndpoint, data)
+}
+
+// RevokeRefreshToken revokes a refresh token (RFC 7009). On Keycloak this also
+// ends the login session, so the tokens issued alongside it stop refreshing.
+func RevokeRefreshToken(ctx context.Context, cfg *Config, refreshToken string) error {
+	metadata, err := discover(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if metadata.RevocationEndpoint == "" {
+		return fmt.Errorf("OIDC provider %s does not advertise a revocation endpoint", cfg.IssuerURL)
+	}
+
+	data := url.Values{}
+	data.Set("token", refreshToken)
+	data.Set("token_type_hint", "refresh_token")
+	data.Set("client_id", cfg.ClientID)
+	if cfg.ClientSecret != "" {
+		data.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", metadata.RevocationEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revocation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := cfg.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("token revocation failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Error is an OAuth error returned by the provider
+type Error struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// JWTExpiry reads the exp claim of a JWT without verifying it.
+// The token is only used to decide when to log in again; the server does the verification.
+func JWTExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,105 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInterrupted is returned when the user cancels an operation with Ctrl-C
+	ErrInterrupted = errors.New("operation cancelled")
+	// ErrTimeout wraps the error of an operation that exceeded its time limit
+	ErrTimeout = errors.New("operation timed out")
+)
+
+// running describes an operation in progress
+type running struct {
+	cancel      context.CancelFunc
+	interrupted bool
+}
+
+var (
+	mu sync.Mutex
+	// inProgress holds every running operation; the full-screen view runs
+	// several at once, e.g. a refresh while an action is applied
+	inProgress = map[*running]struct{}{}
+)
+
+// HandleInterrupts installs the Ctrl-C handler. The first Ctrl-C cancels the
+// running operations and returns to the menu; a Ctrl-C while no operation is
+// running, or a second one before they have stopped, calls exit.
+func HandleInterrupts(exit func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		for range signals {
+			if interrupt() {
+				fmt.Fprintln(os.Stderr, "\nCancelling... press Ctrl-C again to exit")
+				continue
+			}
+
+			fmt.Fprintln(os.Stderr)
+			exit()
+		}
+	}()
+}
+
+// interrupt cancels the running operations that were not cancelled yet and
+// reports whether there were any
+func interrupt() bool {
+	mu.Lock()
+	var cancels []context.CancelFunc
+	for op := range inProgress {
+		if !op.interrupted {
+			op.interrupted = true
+			cancels = append(cancels, op.cancel)
+		}
+	}
+	mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return len(cancels) > 0
+}
+
+// Run calls fn with a context that is cancelled by Ctrl-C or when the timeout
+// expires. A timeout of 0 means no time limit.
+func Run(timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	op := &running{cancel: cancel}
+	mu.Lock()
+	inProgress[op] = struct{}{}
+	mu.Unlock()
+
+	err := fn(ctx)
+
+	mu.Lock()
+	delete(inProgress, op)
+	interrupted := op.interrupted
+	mu.Unlock()
+
+	switch {
+	case err == nil:
+		return nil
+	case interrupted:
+		return ErrInterrupted
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w after %s (use --timeout to allow longer): %v", ErrTimeout, timeout, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInterruptCancelsEveryOperation(t *testing.T) {
+	const n = 2
+	started := make(chan struct{}, n)
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			results <- Run(0, func(ctx context.Context) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		}()
+	}
+	for i := 0; i < n; i++ {
+		<-started
+	}
+
+	if !interrupt() {
+		t.Fatal("interrupt() = false with operations running, want true")
+	}
+	// A second Ctrl-C before they have stopped exits
+	if interrupt() {
+		t.Error("interrupt() = true with every operation already cancelled, want false")
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-results:
+			if !errors.Is(err, ErrInterrupted) {
+				t.Errorf("Run() error = %v, want %v", err, ErrInterrupted)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("operation was not cancelled")
+		}
+	}
+
+	if interrupt() {
+		t.Error("interrupt() = true with no operation running, want false")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	err := Run(time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want %v", err, ErrTimeout)
+	}
+}
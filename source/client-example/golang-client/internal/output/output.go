@@ -0,0 +1,276 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats accepted by -o
+const (
+	Table    = "table"
+	Wide     = "wide"
+	JSON     = "json"
+	YAML     = "yaml"
+	Name     = "name"
+	JSONPath = "jsonpath"
+)
+
+// Format is a parsed -o value
+type Format struct {
+	Kind string
+	// Template is the JSONPath template for the jsonpath format
+	Template string
+}
+
+// Parse parses an -o value: table, wide, json, yaml, name or jsonpath=TEMPLATE
+func Parse(value string) (Format, error) {
+	if template, ok := strings.CutPrefix(value, JSONPath+"="); ok {
+		if template == "" {
+			return Format{}, fmt.Errorf("jsonpath output needs a template, e.g. -o jsonpath='{.items[*].metadata.name}'")
+		}
+		return Format{Kind: JSONPath, Template: template}, nil
+	}
+
+	switch value {
+	case Table, Wide, JSON, YAML, Name:
+		return Format{Kind: value}, nil
+	default:
+		return Format{}, fmt.Errorf("unknown output format %q: use table, wide, json, yaml, name or jsonpath=TEMPLATE", value)
+	}
+}
+
+// Column is one table column
+type Column struct {
+	Header string
+	// Wide columns are only shown with -o wide
+	Wide  bool
+	Value func(obj map[string]interface{}) string
+}
+
+// Kind describes how to print one kind of object
+type Kind struct {
+	// Name is the prefix used by -o name, e.g. "namespace" or "llminferenceservice.serving.kserve.io"
+	Name    string
+	Columns []Column
+	// ObjectName returns the name of an object; metadata.name when nil
+	ObjectName func(obj map[string]interface{}) string
+}
+
+// ToMap converts a typed object or plain struct to the map form used for printing
+func ToMap(obj interface{}) (map[string]interface{}, error) {
+	if runtimeObj, ok := obj.(runtime.Object); ok {
+		result, err := runtime.DefaultUnstructuredConverter.ToUnstructured(runtimeObj)
+		if err != nil {
+			return nil, fmt.Errorf("error converting object: %w", err)
+		}
+		// Typed objects returned by client-go have no apiVersion and kind set
+		if _, ok := result["kind"]; !ok {
+			if gvks, _, err := scheme.Scheme.ObjectKinds(runtimeObj); err == nil && len(gvks) > 0 {
+				result["apiVersion"], result["kind"] = gvks[0].GroupVersion().String(), gvks[0].Kind
+			}
+		}
+		return result, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling object: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error converting object: %w", err)
+	}
+	return result, nil
+}
+
+// PrintList prints objects in the requested format. JSON, YAML and JSONPath
+// see the objects wrapped in a v1 List, like kubectl.
+func PrintList(w io.Writer, format Format, kind Kind, items []map[string]interface{}) error {
+	switch format.Kind {
+	case Table, Wide:
+		return printTable(w, format.Kind == Wide, kind, items)
+	case Name:
+		for _, item := range items {
+			fmt.Fprintf(w, "%s/%s\n", kind.Name, kind.objectName(item))
+		}
+		return nil
+	default:
+		list := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      toInterfaces(items),
+		}
+		return printData(w, format, list)
+	}
+}
+
+// PrintObject prints a single object in the requested format
+func PrintObject(w io.Writer, format Format, kind Kind, item map[string]interface{}) error {
+	switch format.Kind {
+	case Table, Wide:
+		return printTable(w, format.Kind == Wide, kind, []map[string]interface{}{item})
+	case Name:
+		fmt.Fprintf(w, "%s/%s\n", kind.Name, kind.objectName(item))
+		return nil
+	default:
+		return printData(w, format, item)
+	}
+}
+
+// printData prints structured data as JSON, YAML or through a JSONPath template
+func printData(w io.Writer, format Format, data interface{}) error {
+	switch format.Kind {
+	case JSON:
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(out))
+	case YAML:
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error marshaling to YAML: %w", err)
+		}
+		fmt.Fprint(w, string(out))
+	case JSONPath:
+		parser := jsonpath.New("output").AllowMissingKeys(true)
+		if err := parser.Parse(format.Template); err != nil {
+			return fmt.Errorf("invalid jsonpath template %q: %w", format.Template, err)
+		}
+		var buf bytes.Buffer
+		if err := parser.Execute(&buf, data); err != nil {
+			return fmt.Errorf("error executing jsonpath template %q: %w", format.Template, err)
+		}
+		fmt.Fprintln(w, buf.String())
+	default:
+		return fmt.Errorf("unsupported output format %q", format.Kind)
+	}
+	return nil
+}
+
+// printTable prints a NAME column followed by the kind's columns
+func printTable(w io.Writer, wide bool, kind Kind, items []map[string]interface{}) error {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No resources found.")
+		return nil
+	}
+
+	headers, rows := kind.Table(wide, items)
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// Table returns the table headers and one row of cells per item, NAME first.
+// Wide columns are included when wide is set.
+func (k Kind) Table(wide bool, items []map[string]interface{}) ([]string, [][]string) {
+	var columns []Column
+	for _, column := range k.Columns {
+		if wide || !column.Wide {
+			columns = append(columns, column)
+		}
+	}
+
+	headers := []string{"NAME"}
+	for _, column := range columns {
+		headers = append(headers, column.Header)
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := []string{k.objectName(item)}
+		for _, column := range columns {
+			value := column.Value(item)
+			if value == "" {
+				value = "<none>"
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+
+	return headers, rows
+}
+
+// objectName returns the object's name
+func (k Kind) objectName(obj map[string]interface{}) string {
+	if k.ObjectName != nil {
+		return k.ObjectName(obj)
+	}
+	return Field(obj, "metadata", "name")
+}
+
+// Field returns a nested field formatted as a string, or "" when it is missing
+func Field(obj map[string]interface{}, fields ...string) string {
+	value, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil || !found || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// Age returns the time since metadata.creationTimestamp, like kubectl's AGE column
+func Age(obj map[string]interface{}) string {
+	created, err := time.Parse(time.RFC3339, Field(obj, "metadata", "creationTimestamp"))
+	if err != nil {
+		return ""
+	}
+	return duration.HumanDuration(time.Since(created))
+}
+
+// Labels returns the labels as sorted key=value pairs
+func Labels(obj map[string]interface{}) string {
+	labels, _, _ := unstructured.NestedStringMap(obj, "metadata", "labels")
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Annotation returns the value of one annotation
+func Annotation(obj map[string]interface{}, key string) string {
+	return Field(obj, "metadata", "annotations", key)
+}
+
+// toInterfaces converts items for embedding in a List
+func toInterfaces(items []map[string]interface{}) []interface{} {
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
+// Items returns the objects of a dynamic client list
+func Items(list *unstructured.UnstructuredList) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, item.Object)
+	}
+	return items
+}
+
+// StringSlice returns a nested string list joined with commas
+func StringSlice(obj map[string]interface{}, fields ...string) string {
+	values, _, _ := unstructured.NestedStringSlice(obj, fields...)
+	return strings.Join(values, ",")
+}
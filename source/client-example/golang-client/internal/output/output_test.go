@@ -0,0 +1,188 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Format
+		wantErr bool
+	}{
+		{value: "table", want: Format{Kind: Table}},
+		{value: "wide", want: Format{Kind: Wide}},
+		{value: "json", want: Format{Kind: JSON}},
+		{value: "yaml", want: Format{Kind: YAML}},
+		{value: "name", want: Format{Kind: Name}},
+		{value: "jsonpath={.items[*].metadata.name}", want: Format{Kind: JSONPath, Template: "{.items[*].metadata.name}"}},
+		{value: "jsonpath={.a=b}", want: Format{Kind: JSONPath, Template: "{.a=b}"}},
+		{value: "jsonpath=", wantErr: true},
+		{value: "jsonpath", wantErr: true},
+		{value: "JSON", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// testKind prints namespaces with a STATUS column and a wide LABELS column
+var testKind = Kind{
+	Name: "namespace",
+	Columns: []Column{
+		{Header: "STATUS", Value: func(obj map[string]interface{}) string { return Field(obj, "status", "phase") }},
+		{Header: "LABELS", Wide: true, Value: Labels},
+	},
+}
+
+func testItems() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "acme", "labels": map[string]interface{}{"team": "acme", "env": "prod"}},
+			"status":     map[string]interface{}{"phase": "Active"},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "old"},
+		},
+	}
+}
+
+func TestPrintListText(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		items  []map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "table",
+			format: Format{Kind: Table},
+			items:  testItems(),
+			want:   "NAME   STATUS\nacme   Active\nold    <none>\n",
+		},
+		{
+			name:   "wide",
+			format: Format{Kind: Wide},
+			items:  testItems(),
+			want:   "NAME   STATUS   LABELS\nacme   Active   env=prod,team=acme\nold    <none>   <none>\n",
+		},
+		{
+			name:   "empty table",
+			format: Format{Kind: Table},
+			want:   "No resources found.\n",
+		},
+		{
+			name:   "name",
+			format: Format{Kind: Name},
+			items:  testItems(),
+			want:   "namespace/acme\nnamespace/old\n",
+		},
+		{
+			name:   "empty name",
+			format: Format{Kind: Name},
+			want:   "",
+		},
+		{
+			name:   "jsonpath",
+			format: Format{Kind: JSONPath, Template: "{.items[*].metadata.name}"},
+			items:  testItems(),
+			want:   "acme old\n",
+		},
+		{
+			name:   "jsonpath missing key",
+			format: Format{Kind: JSONPath, Template: "{.items[*].spec.missing}"},
+			items:  testItems(),
+			want:   "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := PrintList(&buf, tt.format, testKind, tt.items); err != nil {
+				t.Fatalf("PrintList() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("PrintList() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintListStructured(t *testing.T) {
+	unmarshal := map[string]func([]byte, interface{}) error{
+		JSON: json.Unmarshal,
+		YAML: func(data []byte, v interface{}) error { return yaml.Unmarshal(data, v) },
+	}
+
+	for kind, decode := range unmarshal {
+		t.Run(kind, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := PrintList(&buf, Format{Kind: kind}, testKind, testItems()); err != nil {
+				t.Fatalf("PrintList() error = %v", err)
+			}
+
+			var list struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+				Items      []struct {
+					Metadata struct {
+						Name string `json:"name"`
+					} `json:"metadata"`
+				} `json:"items"`
+			}
+			if err := decode(buf.Bytes(), &list); err != nil {
+				t.Fatalf("output is not valid %s: %v\n%s", kind, err, buf.String())
+			}
+			if list.APIVersion != "v1" || list.Kind != "List" {
+				t.Errorf("list is %s %s, want v1 List", list.APIVersion, list.Kind)
+			}
+			if len(list.Items) != 2 || list.Items[0].Metadata.Name != "acme" || list.Items[1].Metadata.Name != "old" {
+				t.Errorf("list items = %+v, want acme and old", list.Items)
+			}
+		})
+	}
+}
+
+func TestPrintListErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{name: "invalid jsonpath", format: Format{Kind: JSONPath, Template: "{.items[}"}},
+		{name: "unknown format", format: Format{Kind: "xml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := PrintList(&buf, tt.format, testKind, testItems())
+			if err == nil {
+				t.Fatalf("PrintList() printed %q, want an error", buf.String())
+			}
+			if strings.TrimSpace(buf.String()) != "" {
+				t.Errorf("PrintList() printed %q before failing", buf.String())
+			}
+		})
+	}
+}
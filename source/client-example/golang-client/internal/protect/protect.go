@@ -0,0 +1,42 @@
+package protect
+
+import (
+	"fmt"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Label marks a project, user or model that must not be deleted, e.g.
+// `oc label user alice bakerapps.net/protected=true`
+const Label = "bakerapps.net/protected"
+
+// Error reports a deletion refused because the object is protected
+type Error struct {
+	Kind   string
+	Name   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("refusing to delete %s %q: it is protected (%s)", e.Kind, e.Name, e.Reason)
+}
+
+// Check returns an *Error if the object is labelled as protected
+func Check(kind string, obj metav1.Object) error {
+	if obj.GetLabels()[Label] == "true" {
+		return &Error{Kind: kind, Name: obj.GetName(), Reason: fmt.Sprintf("labelled %s=true", Label)}
+	}
+	return nil
+}
+
+// CheckNamespace returns an *Error if the namespace matches one of the
+// protected patterns, such as "openshift-*", or is labelled as protected
+func CheckNamespace(patterns []string, namespace metav1.Object) error {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace.GetName()); matched {
+			return &Error{Kind: "project", Name: namespace.GetName(), Reason: fmt.Sprintf("matches protected namespace pattern %q", pattern)}
+		}
+	}
+	return Check("project", namespace)
+}
@@ -0,0 +1,84 @@
+package protect
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		protected bool
+	}{
+		{name: "no labels"},
+		{name: "other label", labels: map[string]string{"team": "acme"}},
+		{name: "labelled true", labels: map[string]string{Label: "true"}, protected: true},
+		{name: "labelled false", labels: map[string]string{Label: "false"}},
+		{name: "labelled empty", labels: map[string]string{Label: ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check("user", namespace("alice", tt.labels))
+			if got := err != nil; got != tt.protected {
+				t.Fatalf("Check() error = %v, want protected %v", err, tt.protected)
+			}
+			if !tt.protected {
+				return
+			}
+			var protectErr *Error
+			if !errors.As(err, &protectErr) {
+				t.Fatalf("Check() error = %T, want *Error", err)
+			}
+			if protectErr.Kind != "user" || protectErr.Name != "alice" {
+				t.Errorf("Check() = %s %s, want user alice", protectErr.Kind, protectErr.Name)
+			}
+		})
+	}
+}
+
+func TestCheckNamespace(t *testing.T) {
+	defaults := []string{"openshift-*", "kube-*", "redhat-ods-*", "maas-api", "opendatahub"}
+
+	tests := []struct {
+		name      string
+		patterns  []string
+		namespace string
+		labels    map[string]string
+		protected bool
+	}{
+		{name: "prefix pattern", patterns: defaults, namespace: "openshift-ingress", protected: true},
+		{name: "kube system", patterns: defaults, namespace: "kube-system", protected: true},
+		{name: "exact name", patterns: defaults, namespace: "maas-api", protected: true},
+		{name: "exact name only", patterns: defaults, namespace: "maas-api-test"},
+		{name: "pattern needs the dash", patterns: defaults, namespace: "openshift", protected: false},
+		{name: "tenant namespace", patterns: defaults, namespace: "acme-inc-models"},
+		{name: "no patterns", patterns: nil, namespace: "openshift-ingress"},
+		{name: "empty list", patterns: []string{}, namespace: "kube-system"},
+		{name: "labelled tenant", patterns: defaults, namespace: "acme-inc-models", labels: map[string]string{Label: "true"}, protected: true},
+		{name: "labelled without patterns", patterns: nil, namespace: "acme-inc-models", labels: map[string]string{Label: "true"}, protected: true},
+		{name: "single character wildcard", patterns: []string{"team-?"}, namespace: "team-a", protected: true},
+		{name: "invalid pattern matches nothing", patterns: []string{"["}, namespace: "["},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckNamespace(tt.patterns, namespace(tt.namespace, tt.labels))
+			if got := err != nil; got != tt.protected {
+				t.Fatalf("CheckNamespace(%q) error = %v, want protected %v", tt.namespace, err, tt.protected)
+			}
+			var protectErr *Error
+			if tt.protected && (!errors.As(err, &protectErr) || protectErr.Kind != "project") {
+				t.Errorf("CheckNamespace(%q) error = %v, want a project *Error", tt.namespace, err)
+			}
+		})
+	}
+}
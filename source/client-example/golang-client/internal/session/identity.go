@@ -0,0 +1,40 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// identityTimeout bounds the identity lookup at startup
+const identityTimeout = 10 * time.Second
+
+// lookupUser asks the API server who the session is authenticated as.
+// It tries SelfSubjectReview first, then OpenShift's "~" user, and finally
+// falls back to the configured username.
+func lookupUser(s *Session) string {
+	ctx, cancel := context.WithTimeout(context.Background(), identityTimeout)
+	defer cancel()
+
+	review, err := s.Clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil && review.Status.UserInfo.Username != "" {
+		return review.Status.UserInfo.Username
+	}
+
+	userResource := schema.GroupVersionResource{Group: "user.openshift.io", Version: "v1", Resource: "users"}
+	user, err := s.Dynamic.Resource(userResource).Get(ctx, "~", metav1.GetOptions{})
+	if err == nil {
+		if name, found, _ := unstructured.NestedString(user.Object, "metadata", "name"); found {
+			return name
+		}
+	}
+
+	if s.Auth.Username != "" {
+		return s.Auth.Username
+	}
+	return "unknown"
+}
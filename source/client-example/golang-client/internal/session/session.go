@@ -0,0 +1,214 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/auth"
+	"github.com/bryon/ocp-lister/internal/client"
+	"github.com/bryon/ocp-lister/internal/config"
+	"github.com/bryon/ocp-lister/internal/operation"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Session holds the authenticated clients shared by every handler.
+// It is built once at startup so that each menu action reuses the same
+// credentials instead of logging in again.
+type Session struct {
+	Auth      *auth.Config
+	Settings  config.Settings
+	Profile   string
+	Config    *rest.Config
+	Clientset kubernetes.Interface
+	Dynamic   dynamic.Interface
+	Discovery discovery.DiscoveryInterface
+
+	// Tokens is the OAuth token source, or nil when kubeconfig credentials are used
+	Tokens *client.TokenSource
+
+	// Cluster is a display name for the cluster (profile, context or server URL)
+	Cluster string
+	// User is the authenticated user as reported by the API server
+	User string
+
+	// Out receives the objects handlers print, os.Stdout unless redirected with WithOutput
+	Out io.Writer
+	// Status receives progress, confirmations, diffs and warnings. It is the
+	// same writer as Out, so the menu and REPL read in order; subcommands move
+	// it to stderr with WithStatus, so piped -o json|yaml|name output only
+	// carries objects.
+	Status io.Writer
+}
+
+// New authenticates once and creates the typed, dynamic and discovery clients
+func New(cfg *config.Config) (*Session, error) {
+	restConfig, tokens, err := client.GetRESTConfig(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get REST config: %w", err)
+	}
+
+	sess, err := NewForConfig(cfg.Auth, restConfig)
+	if err != nil {
+		return nil, err
+	}
+	sess.Settings = cfg.Settings
+	sess.Profile = cfg.ProfileName
+	sess.Tokens = tokens
+	sess.Cluster = clusterName(cfg, restConfig)
+	sess.User = lookupUser(sess)
+
+	if cfg.Auth.ImpersonateUser != "" {
+		return sess.Impersonate(cfg.Auth.ImpersonateUser, cfg.Auth.ImpersonateGroups)
+	}
+
+	return sess, nil
+}
+
+// NewForConfig creates a session from an existing REST config
+func NewForConfig(authConfig *auth.Config, config *rest.Config) (*Session, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	return &Session{
+		Auth:      authConfig,
+		Config:    config,
+		Clientset: clientset,
+		Dynamic:   dynamicClient,
+		Discovery: discoveryClient,
+		Cluster:   config.Host,
+		Out:       os.Stdout,
+		Status:    os.Stdout,
+	}, nil
+}
+
+// Impersonate returns a session whose requests act as the given user and groups,
+// reusing the current credentials. An empty user stops impersonating.
+func (s *Session) Impersonate(user string, groups []string) (*Session, error) {
+	if user == "" && len(groups) > 0 {
+		return nil, fmt.Errorf("impersonating groups requires a user")
+	}
+
+	config := rest.CopyConfig(s.Config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: user,
+		Groups:   groups,
+	}
+
+	authConfig := *s.Auth
+	authConfig.ImpersonateUser = user
+	authConfig.ImpersonateGroups = groups
+
+	sess, err := NewForConfig(&authConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	sess.Settings = s.Settings
+	sess.Profile = s.Profile
+	sess.Tokens = s.Tokens
+	sess.Cluster = s.Cluster
+	sess.User = s.User
+	sess.Out = s.Out
+	sess.Status = s.Status
+
+	return sess, nil
+}
+
+// WithOutput returns a copy of the session whose handlers write both objects
+// and status messages to w
+func (s *Session) WithOutput(w io.Writer) *Session {
+	sess := *s
+	sess.Out = w
+	sess.Status = w
+	return &sess
+}
+
+// WithStatus returns a copy of the session whose status messages go to w
+func (s *Session) WithStatus(w io.Writer) *Session {
+	sess := *s
+	sess.Status = w
+	return &sess
+}
+
+// Impersonating describes the impersonated identity, or returns "" when not impersonating
+func (s *Session) Impersonating() string {
+	user := s.Config.Impersonate.UserName
+	if user == "" {
+		return ""
+	}
+	if len(s.Config.Impersonate.Groups) == 0 {
+		return user
+	}
+	return fmt.Sprintf("%s (groups: %s)", user, strings.Join(s.Config.Impersonate.Groups, ", "))
+}
+
+// Describe returns a one-line summary of the cluster and identity for menu headers
+func (s *Session) Describe() string {
+	cluster := s.Cluster
+	if cluster != s.Config.Host {
+		cluster = fmt.Sprintf("%s (%s)", s.Cluster, s.Config.Host)
+	}
+
+	line := fmt.Sprintf("Cluster: %s | User: %s", cluster, s.User)
+	if who := s.Impersonating(); who != "" {
+		line += " | Acting as: " + who
+	}
+	return line
+}
+
+// clusterName picks a display name: the profile, the kubeconfig context or the server URL
+func clusterName(cfg *config.Config, restConfig *rest.Config) string {
+	if cfg.ProfileName != "" {
+		return cfg.ProfileName
+	}
+	if cfg.Auth.KubeContext != "" {
+		return cfg.Auth.KubeContext
+	}
+	if cfg.Auth.Method == auth.MethodKubeconfig {
+		if kubeconfig, err := clientcmd.LoadFromFile(auth.KubeconfigPath()); err == nil && kubeconfig.CurrentContext != "" {
+			return kubeconfig.CurrentContext
+		}
+	}
+	return restConfig.Host
+}
+
+// Logout revokes the token this session obtained at login and removes it from
+// the on-disk cache. It reports false when the credentials are not the tool's
+// own (kubeconfig, bearer token or in-cluster service account).
+func (s *Session) Logout() (bool, error) {
+	if s.Tokens == nil {
+		return false, nil
+	}
+	return true, s.Tokens.Revoke()
+}
+
+// Run runs one menu operation with the configured timeout. Ctrl-C cancels it
+// and returns to the menu instead of ending the program.
+func (s *Session) Run(fn func(ctx context.Context) error) error {
+	return operation.Run(s.Settings.Timeout, fn)
+}
+
+// RunFor runs one operation like Run, with its own time limit instead of the
+// configured one, e.g. while waiting for a deletion to finish
+func (s *Session) RunFor(timeout time.Duration, fn func(ctx context.Context) error) error {
+	return operation.Run(timeout, fn)
+}
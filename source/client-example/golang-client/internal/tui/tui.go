@@ -0,0 +1,516 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// confirmDetailLines is the most lines of details the confirm dialog shows
+const confirmDetailLines = 20
+
+// ui is the state of the full-screen interface
+type ui struct {
+	sess *session.Session
+	app  *tview.Application
+
+	pages   *tview.Pages
+	header  *tview.TextView
+	sidebar *tview.List
+	table   *tview.Table
+	detail  *tview.TextView
+	log     *tview.TextView
+	status  *tview.TextView
+
+	view      *view
+	namespace string
+	// generation identifies the latest load, so results of older ones are dropped
+	generation int
+
+	kind    output.Kind
+	items   []map[string]interface{}
+	headers []string
+	rows    [][]string
+	// order holds the indexes of items and rows in display order
+	order      []int
+	sortColumn int
+	sortDesc   bool
+}
+
+// Run shows the full-screen interface until the user quits with q
+func Run(sess *session.Session) error {
+	u := &ui{
+		sess:      sess,
+		app:       tview.NewApplication(),
+		namespace: sess.Settings.ModelNamespace,
+	}
+	u.build()
+	u.selectView(0)
+
+	return u.app.SetRoot(u.pages, true).SetFocus(u.sidebar).Run()
+}
+
+// build creates the widgets and lays them out
+func (u *ui) build() {
+	u.header = tview.NewTextView().SetDynamicColors(true)
+
+	u.sidebar = tview.NewList().ShowSecondaryText(false)
+	u.sidebar.SetBorder(true).SetTitle(" Resources ")
+	for i := range views {
+		index := i
+		u.sidebar.AddItem(views[i].title, "", rune('1'+i), func() {
+			u.selectView(index)
+			u.app.SetFocus(u.table)
+		})
+	}
+	u.sidebar.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		u.selectView(index)
+	})
+
+	u.table = tview.NewTable().SetFixed(1, 1).SetSelectable(true, false)
+	u.table.SetBorder(true)
+	u.table.SetSelectionChangedFunc(func(row, _ int) {
+		u.showDetail(row)
+	})
+
+	u.detail = tview.NewTextView().SetScrollable(true)
+	u.detail.SetBorder(true).SetTitle(" Detail ")
+
+	u.log = tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	u.log.SetBorder(true).SetTitle(" Output ")
+
+	u.status = tview.NewTextView().SetDynamicColors(true)
+
+	content := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.table, 0, 3, false).
+		AddItem(u.detail, 0, 2, false)
+	body := tview.NewFlex().
+		AddItem(u.sidebar, 30, 0, true).
+		AddItem(content, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.header, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(u.log, 7, 0, false).
+		AddItem(u.status, 1, 0, false)
+
+	u.pages = tview.NewPages().AddPage("main", layout, true, true)
+	u.app.SetInputCapture(u.handleKey)
+}
+
+// handleKey implements the keyboard shortcuts of the main page
+func (u *ui) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if name, _ := u.pages.GetFrontPage(); name != "main" {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if u.app.GetFocus() == u.table {
+			u.app.SetFocus(u.sidebar)
+		} else {
+			u.app.SetFocus(u.table)
+		}
+		return nil
+	case tcell.KeyEsc:
+		u.app.SetFocus(u.sidebar)
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	key := event.Rune()
+	switch {
+	case key == 'q':
+		u.app.Stop()
+	case key >= '1' && key < rune('1'+len(views)):
+		u.sidebar.SetCurrentItem(int(key - '1'))
+	case key == 'r':
+		u.load()
+	case key == 's':
+		u.sortColumn = (u.sortColumn + 1) % max(len(u.headers), 1)
+		u.sortDesc = false
+		u.render()
+	case key == 'S':
+		u.sortDesc = !u.sortDesc
+		u.render()
+	case key == 'n' && u.view.namespaced:
+		u.prompt("Namespace", u.namespace, func(namespace string) {
+			u.namespace = namespace
+			u.load()
+		})
+	case key == '?':
+		u.showHelp()
+	default:
+		for i := range u.view.actions {
+			if u.view.actions[i].key == key {
+				u.startAction(&u.view.actions[i])
+				return nil
+			}
+		}
+		return event
+	}
+
+	return nil
+}
+
+// selectView switches the table to a sidebar entry
+func (u *ui) selectView(index int) {
+	if u.view == &views[index] {
+		return
+	}
+	u.view = &views[index]
+	u.sortColumn, u.sortDesc = 0, false
+	u.updateStatus()
+	u.load()
+}
+
+// load fetches the objects of the current view in the background
+func (u *ui) load() {
+	u.generation++
+	generation := u.generation
+	v := u.view
+	namespace := u.namespace
+
+	u.items, u.rows, u.order = nil, nil, nil
+	u.updateHeader()
+	u.table.Clear().SetTitle(fmt.Sprintf(" %s (loading...) ", v.title))
+	u.detail.Clear()
+
+	go func() {
+		var kind output.Kind
+		var items []map[string]interface{}
+		err := u.sess.Run(func(ctx context.Context) error {
+			var err error
+			kind, items, err = v.list(ctx, u.sess, namespace)
+			return err
+		})
+
+		u.app.QueueUpdateDraw(func() {
+			if generation != u.generation {
+				return
+			}
+			if err != nil {
+				u.table.SetTitle(fmt.Sprintf(" %s ", v.title))
+				u.logError(err)
+				return
+			}
+			u.kind, u.items = kind, items
+			u.headers, u.rows = kind.Table(true, items)
+			u.render()
+		})
+	}()
+}
+
+// render sorts the rows and fills the table
+func (u *ui) render() {
+	u.table.Clear()
+	u.table.SetTitle(fmt.Sprintf(" %s (%d) ", u.view.title, len(u.rows)))
+	if u.sortColumn >= len(u.headers) {
+		u.sortColumn = 0
+	}
+
+	for column, header := range u.headers {
+		if column == u.sortColumn && u.sortDesc {
+			header += " ▼"
+		} else if column == u.sortColumn {
+			header += " ▲"
+		}
+		u.table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	u.order = make([]int, len(u.rows))
+	for i := range u.order {
+		u.order[i] = i
+	}
+	sort.SliceStable(u.order, func(a, b int) bool {
+		if u.sortDesc {
+			return u.less(u.order[b], u.order[a])
+		}
+		return u.less(u.order[a], u.order[b])
+	})
+
+	for row, index := range u.order {
+		for column, value := range u.rows[index] {
+			u.table.SetCell(row+1, column, tview.NewTableCell(tview.Escape(value)).SetExpansion(1))
+		}
+	}
+
+	if len(u.rows) > 0 {
+		u.table.Select(1, 0)
+		u.showDetail(1)
+	} else {
+		u.detail.SetText("No resources found.")
+	}
+}
+
+// less orders two rows by the sort column: AGE by creation time, numbers numerically
+func (u *ui) less(a, b int) bool {
+	if u.headers[u.sortColumn] == "AGE" {
+		// An older object has the larger age
+		return output.Field(u.items[a], "metadata", "creationTimestamp") > output.Field(u.items[b], "metadata", "creationTimestamp")
+	}
+
+	x, y := u.rows[a][u.sortColumn], u.rows[b][u.sortColumn]
+	if nx, err := strconv.ParseFloat(x, 64); err == nil {
+		if ny, err := strconv.ParseFloat(y, 64); err == nil {
+			return nx < ny
+		}
+	}
+	return x < y
+}
+
+// showDetail shows the object in a table row as YAML
+func (u *ui) showDetail(row int) {
+	if row < 1 || row > len(u.order) {
+		return
+	}
+
+	var buf bytes.Buffer
+	item := u.items[u.order[row-1]]
+	if err := output.PrintObject(&buf, output.Format{Kind: output.YAML}, u.kind, item); err != nil {
+		buf.WriteString(err.Error())
+	}
+	u.detail.SetText(buf.String()).ScrollToBeginning()
+	u.detail.SetTitle(fmt.Sprintf(" %s ", u.rows[u.order[row-1]][0]))
+}
+
+// selected returns the name of the object in the selected row, or ""
+func (u *ui) selected() string {
+	row, _ := u.table.GetSelection()
+	if row < 1 || row > len(u.order) {
+		return ""
+	}
+	return u.rows[u.order[row-1]][0]
+}
+
+// startAction asks for a name or confirmation as the action needs, then runs it
+func (u *ui) startAction(act *action) {
+	if act.prompt != "" {
+		u.prompt(act.prompt, "", func(name string) { u.runAction(act, name) })
+		return
+	}
+
+	name := u.selected()
+	if name == "" {
+		u.logError(fmt.Errorf("select an object to %s", act.label))
+		return
+	}
+
+	if act.ask != "" {
+		u.prompt(act.ask, "", func(value string) {
+			edit := *act
+			edit.run = func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return act.edit(ctx, sess, name, value)
+			}
+			u.runAction(&edit, name)
+		})
+		return
+	}
+
+	if act.confirm && act.describe != nil {
+		u.describeAndConfirm(act, name)
+		return
+	}
+	if act.confirm {
+		u.confirm(name, act.label, "", func() {
+			u.runAction(act, name)
+		})
+		return
+	}
+
+	u.runAction(act, name)
+}
+
+// describeAndConfirm runs the action's describe step in the background and
+// shows its output in the confirm dialog, so what will be destroyed is seen
+// before the name is typed
+func (u *ui) describeAndConfirm(act *action, name string) {
+	u.logf("[yellow]Checking %s...[-]", tview.Escape(name))
+	namespace := u.namespace
+
+	go func() {
+		var buf bytes.Buffer
+		sess := u.sess.WithOutput(&buf)
+		err := sess.Run(func(ctx context.Context) error {
+			return act.describe(ctx, sess, name, namespace)
+		})
+
+		u.app.QueueUpdateDraw(func() {
+			if err != nil {
+				u.logError(err)
+				return
+			}
+			u.confirm(name, act.label, strings.TrimSpace(buf.String()), func() {
+				u.runAction(act, name)
+			})
+		})
+	}()
+}
+
+// runAction runs a handler in the background, shows its output and reloads the table
+func (u *ui) runAction(act *action, name string) {
+	u.logf("[yellow]%s %s...[-]", act.label, tview.Escape(name))
+	namespace := u.namespace
+
+	go func() {
+		var buf bytes.Buffer
+		sess := u.sess.WithOutput(&buf)
+		err := sess.Run(func(ctx context.Context) error {
+			return act.run(ctx, sess, name, namespace)
+		})
+
+		u.app.QueueUpdateDraw(func() {
+			if text := strings.TrimSpace(buf.String()); text != "" {
+				u.logf("%s", tview.Escape(text))
+			}
+			if err != nil {
+				u.logError(err)
+			}
+			u.load()
+		})
+	}()
+}
+
+// prompt shows a dialog asking for a value
+func (u *ui) prompt(label, value string, done func(string)) {
+	form := tview.NewForm()
+	form.AddInputField(label, value, 40, nil, nil)
+	dismiss := func() {
+		u.pages.RemovePage("prompt")
+		u.app.SetFocus(u.table)
+	}
+	form.AddButton("OK", func() {
+		text := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		dismiss()
+		if text != "" {
+			done(text)
+		}
+	})
+	form.AddButton("Cancel", dismiss)
+	form.SetCancelFunc(dismiss)
+	form.SetBorder(true).SetTitle(" " + label + " ")
+
+	u.pages.AddPage("prompt", centered(form, 60, 7), true, true)
+	u.app.SetFocus(form)
+}
+
+// confirm shows a dialog, with details above the form if given, that runs ok
+// only when the object's name is typed, so a stray key cannot confirm a delete
+func (u *ui) confirm(name, label, details string, ok func()) {
+	form := tview.NewForm()
+	form.AddInputField("Type the name to confirm", "", 30, nil, nil)
+	dismiss := func() {
+		u.pages.RemovePage("confirm")
+		u.app.SetFocus(u.table)
+	}
+	form.AddButton(label, func() {
+		typed := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		dismiss()
+		if typed != name {
+			u.logError(fmt.Errorf("'%s' does not match '%s'; %s cancelled", typed, name, label))
+			return
+		}
+		ok()
+	})
+	form.AddButton("Cancel", dismiss)
+	form.SetCancelFunc(dismiss)
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" %s %s? ", label, name))
+
+	if details == "" {
+		u.pages.AddPage("confirm", centered(form, 60, 7), true, true)
+		u.app.SetFocus(form)
+		return
+	}
+
+	text := tview.NewTextView().SetText(details)
+	text.SetBorder(true).SetTitle(fmt.Sprintf(" %s ", name))
+	height := min(strings.Count(details, "\n")+3, confirmDetailLines)
+	dialog := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(text, height, 0, false).
+		AddItem(form, 7, 0, true)
+	u.pages.AddPage("confirm", centered(dialog, 80, height+7), true, true)
+	u.app.SetFocus(form)
+}
+
+// showHelp lists the keyboard shortcuts
+func (u *ui) showHelp() {
+	help := []string{
+		"1-6        select a resource",
+		"Tab / Esc  switch between the sidebar and the table",
+		"Up / Down  move; the detail pane shows the selected object",
+		"s / S      sort by the next column / reverse the order",
+		"r          refresh",
+		"n          change namespace (Models)",
+		"q          quit",
+	}
+	for _, v := range views {
+		for _, act := range v.actions {
+			help = append(help, fmt.Sprintf("%c          %s (%s)", act.key, act.label, v.title))
+		}
+	}
+
+	modal := tview.NewModal().
+		SetText(strings.Join(help, "\n")).
+		AddButtons([]string{"Close"}).
+		SetDoneFunc(func(int, string) {
+			u.pages.RemovePage("help")
+			u.app.SetFocus(u.table)
+		})
+	u.pages.AddPage("help", modal, true, true)
+	u.app.SetFocus(modal)
+}
+
+// updateHeader shows the cluster, identity and namespace
+func (u *ui) updateHeader() {
+	text := tview.Escape(u.sess.Describe())
+	if u.view.namespaced {
+		text += fmt.Sprintf("  [yellow]namespace:[-] %s", tview.Escape(u.namespace))
+	}
+	u.header.SetText(text)
+}
+
+// updateStatus lists the shortcuts of the current view
+func (u *ui) updateStatus() {
+	keys := []string{"[yellow]1-6[-] view", "[yellow]Tab[-] focus", "[yellow]s/S[-] sort", "[yellow]r[-] refresh"}
+	if u.view.namespaced {
+		keys = append(keys, "[yellow]n[-] namespace")
+	}
+	for _, act := range u.view.actions {
+		keys = append(keys, fmt.Sprintf("[yellow]%c[-] %s", act.key, act.label))
+	}
+	keys = append(keys, "[yellow]?[-] help", "[yellow]q[-] quit")
+	u.status.SetText(strings.Join(keys, "  "))
+}
+
+// logf appends a line to the output pane
+func (u *ui) logf(format string, args ...interface{}) {
+	fmt.Fprintf(u.log, format+"\n", args...)
+	u.log.ScrollToEnd()
+}
+
+// logError appends an error to the output pane
+func (u *ui) logError(err error) {
+	u.logf("[red]Error: %s[-]", tview.Escape(err.Error()))
+}
+
+// centered places a primitive of the given size in the middle of the screen
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
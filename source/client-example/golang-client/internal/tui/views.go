@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bryon/ocp-lister/internal/objects/clusterrolebindings"
+	"github.com/bryon/ocp-lister/internal/objects/groups"
+	"github.com/bryon/ocp-lister/internal/objects/models"
+	"github.com/bryon/ocp-lister/internal/objects/projects"
+	"github.com/bryon/ocp-lister/internal/objects/tiers"
+	"github.com/bryon/ocp-lister/internal/objects/users"
+	"github.com/bryon/ocp-lister/internal/output"
+	"github.com/bryon/ocp-lister/internal/session"
+)
+
+// view is one entry of the sidebar
+type view struct {
+	title string
+	// namespaced views list one namespace, changed with the n key
+	namespaced bool
+	list       func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error)
+	actions    []action
+}
+
+// action is a keyboard shortcut that runs a handler
+type action struct {
+	key   rune
+	label string
+	// prompt asks for the name of a new object instead of using the selected row
+	prompt string
+	// confirm asks before running, e.g. for deletes
+	confirm bool
+	// describe, if set, shows what a confirm action will destroy in the dialog
+	describe func(ctx context.Context, sess *session.Session, name, namespace string) error
+	run      func(ctx context.Context, sess *session.Session, name, namespace string) error
+	// ask prompts for a value to apply to the selected row, which edit is
+	// called with instead of run
+	ask  string
+	edit func(ctx context.Context, sess *session.Session, name, value string) error
+}
+
+// views lists the sidebar entries in order; the digit keys select them
+var views = []view{
+	{
+		title: "Projects",
+		list: func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+			return projects.List(ctx, sess)
+		},
+		actions: []action{
+			{key: 'c', label: "create", prompt: "Project name", run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return projects.HandleCreate(ctx, sess, name, projects.CreateOptions{})
+			}},
+			{key: 'u', label: "relabel", ask: "Labels (key=value, key- removes)", edit: func(ctx context.Context, sess *session.Session, name, value string) error {
+				return projects.HandleUpdate(ctx, sess, name, projects.UpdateOptions{Labels: strings.Fields(value)})
+			}},
+			{key: 'd', label: "delete", confirm: true, describe: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return projects.DescribeForDeletion(ctx, sess, name)
+			}, run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return projects.Delete(ctx, sess, name, projects.DeleteOptions{})
+			}},
+			{key: 'a', label: "annotate", run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return projects.HandleAddAnnotation(ctx, sess, name)
+			}},
+		},
+	},
+	{
+		title: "Groups",
+		list: func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+			return groups.List(ctx, sess)
+		},
+	},
+	{
+		title: "Users",
+		list: func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+			return users.List(ctx, sess)
+		},
+		actions: []action{
+			{key: 'c', label: "create", prompt: "User name", run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return users.HandleCreate(ctx, sess, name)
+			}},
+			{key: 'd', label: "delete", confirm: true, run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return users.HandleDelete(ctx, sess, name)
+			}},
+			{key: 'a', label: "annotate", run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return users.HandleAddAnnotation(ctx, sess, name)
+			}},
+		},
+	},
+	{
+		title: "Cluster Role Bindings",
+		list: func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+			return clusterrolebindings.List(ctx, sess)
+		},
+		actions: []action{
+			{key: 'a', label: "annotate", run: func(ctx context.Context, sess *session.Session, name, namespace string) error {
+				return clusterrolebindings.HandleAddAnnotation(ctx, sess, name)
+			}},
+		},
+	},
+	{
+		title:      "Models",
+		namespaced: true,
+		list:       models.List,
+		actions: []action{
+			{key: 'c', label: "deploy", prompt: "Model name", run: models.HandleDeploy},
+			{key: 'd', label: "undeploy", confirm: true, run: models.HandleUndeploy},
+		},
+	},
+	{
+		title: "Tiers",
+		list: func(ctx context.Context, sess *session.Session, namespace string) (output.Kind, []map[string]interface{}, error) {
+			return tiers.List(ctx, sess)
+		},
+	},
+}
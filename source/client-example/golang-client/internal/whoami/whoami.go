@@ -0,0 +1,159 @@
+package whoami
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bryon/ocp-lister/internal/client"
+	"github.com/bryon/ocp-lister/internal/objects/tiers"
+	"github.com/bryon/ocp-lister/internal/session"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Identity describes who the session acts as
+type Identity struct {
+	Username string   `json:"username"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups"`
+	// FullName and Identities come from the OpenShift "~" user
+	FullName   string   `json:"fullName,omitempty"`
+	Identities []string `json:"identities,omitempty"`
+
+	// Tier is the MaaS tier the groups resolve to, granted by TierGroup
+	Tier      *tiers.Tier `json:"tier,omitempty"`
+	TierGroup string      `json:"tierGroup,omitempty"`
+	// TierError explains why the tier could not be resolved
+	TierError string `json:"tierError,omitempty"`
+
+	// TokenExpiry is zero when the credentials do not expire or it is unknown
+	TokenExpiry time.Time `json:"tokenExpiry,omitempty"`
+	// TokenNote explains a missing TokenExpiry
+	TokenNote string `json:"tokenNote,omitempty"`
+}
+
+// Lookup asks the API server who the session acts as. The user and groups come
+// from a SelfSubjectReview, falling back to OpenShift's "~" user on clusters
+// without that API. Tier and token expiry problems are reported in the result
+// rather than as errors.
+func Lookup(ctx context.Context, sess *session.Session) (*Identity, error) {
+	identity := &Identity{}
+
+	review, reviewErr := sess.Clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if reviewErr == nil {
+		identity.Username = review.Status.UserInfo.Username
+		identity.UID = review.Status.UserInfo.UID
+		identity.Groups = review.Status.UserInfo.Groups
+	}
+
+	userResource := schema.GroupVersionResource{Group: "user.openshift.io", Version: "v1", Resource: "users"}
+	user, userErr := sess.Dynamic.Resource(userResource).Get(ctx, "~", metav1.GetOptions{})
+	if userErr == nil {
+		identity.FullName, _, _ = unstructured.NestedString(user.Object, "fullName")
+		identity.Identities, _, _ = unstructured.NestedStringSlice(user.Object, "identities")
+		if reviewErr != nil {
+			identity.Username = user.GetName()
+			identity.UID = string(user.GetUID())
+			identity.Groups, _, _ = unstructured.NestedStringSlice(user.Object, "groups")
+		}
+	}
+
+	if reviewErr != nil && userErr != nil {
+		return nil, fmt.Errorf("failed to look up identity: %w", reviewErr)
+	}
+
+	tierList, err := tiers.ListTiers(ctx, sess)
+	if err != nil {
+		identity.TierError = err.Error()
+	} else if tier, group := tiers.Resolve(tierList, identity.Groups); tier != nil {
+		identity.Tier, identity.TierGroup = tier, group
+	} else {
+		identity.TierError = "none of your groups is mapped to a tier"
+	}
+
+	identity.TokenExpiry, identity.TokenNote = tokenExpiry(ctx, sess)
+
+	return identity, nil
+}
+
+// tokenExpiry finds when the session's token expires, or explains why it cannot
+func tokenExpiry(ctx context.Context, sess *session.Session) (time.Time, string) {
+	if sess.Tokens != nil {
+		if token := sess.Tokens.Current(); token != nil && !token.ExpiresAt.IsZero() {
+			return token.ExpiresAt, ""
+		}
+		return time.Time{}, "unknown"
+	}
+
+	token, err := client.BearerToken(sess.Config)
+	if err != nil {
+		return time.Time{}, err.Error()
+	}
+	if token == "" {
+		return time.Time{}, "not applicable (client certificate)"
+	}
+
+	expiry, ok, err := client.TokenExpiry(ctx, sess.Config, token)
+	if err != nil {
+		return time.Time{}, err.Error()
+	}
+	if !ok {
+		return time.Time{}, "does not expire"
+	}
+	return expiry, ""
+}
+
+// HandleShow prints the identity panel
+func HandleShow(ctx context.Context, sess *session.Session) error {
+	identity, err := Lookup(ctx, sess)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(sess.Out)
+	fmt.Fprintf(sess.Out, "Cluster:       %s (%s)\n", sess.Cluster, sess.Config.Host)
+	fmt.Fprintf(sess.Out, "User:          %s\n", identity.Username)
+	if identity.FullName != "" {
+		fmt.Fprintf(sess.Out, "Full name:     %s\n", identity.FullName)
+	}
+	if identity.UID != "" {
+		fmt.Fprintf(sess.Out, "UID:           %s\n", identity.UID)
+	}
+	if len(identity.Identities) > 0 {
+		fmt.Fprintf(sess.Out, "Identities:    %s\n", strings.Join(identity.Identities, ", "))
+	}
+	if who := sess.Impersonating(); who != "" {
+		fmt.Fprintf(sess.Out, "Logged in as:  %s, impersonating %s\n", sess.User, who)
+	}
+
+	fmt.Fprintf(sess.Out, "Groups:        ")
+	if len(identity.Groups) == 0 {
+		fmt.Fprintln(sess.Out, "(none)")
+	} else {
+		fmt.Fprintln(sess.Out, strings.Join(identity.Groups, ", "))
+	}
+
+	if identity.Tier != nil {
+		fmt.Fprintf(sess.Out, "MaaS tier:     %s (level %d, via group %s)\n", identity.Tier.Name, identity.Tier.Level, identity.TierGroup)
+	} else {
+		fmt.Fprintf(sess.Out, "MaaS tier:     unknown (%s)\n", identity.TierError)
+	}
+
+	if !identity.TokenExpiry.IsZero() {
+		remaining := time.Until(identity.TokenExpiry).Round(time.Minute)
+		if remaining > 0 {
+			fmt.Fprintf(sess.Out, "Token expires: %s (in %s)\n", identity.TokenExpiry.Local().Format("2006-01-02 15:04:05"), remaining)
+		} else {
+			fmt.Fprintf(sess.Out, "Token expires: %s (expired)\n", identity.TokenExpiry.Local().Format("2006-01-02 15:04:05"))
+		}
+	} else {
+		fmt.Fprintf(sess.Out, "Token expires: %s\n", identity.TokenNote)
+	}
+	fmt.Fprintln(sess.Out)
+
+	return nil
+}
@@ -0,0 +1,26 @@
+#!/usr/bin/env bash
+# Provision the MaaS tenant namespaces used by components/platform/model-deployments.
+# Each tenant gets its project, an edit RoleBinding for its group, a ResourceQuota
+# (including requests.nvidia.com/gpu), a LimitRange and a NetworkPolicy that only
+# admits the MaaS gateway. Tenants that already exist are completed, not changed.
+# Requires: ocp-lister (source/client-example/golang-client) logged in as cluster-admin.
+set -euo pipefail
+
+OCP_LISTER="${OCP_LISTER:-ocp-lister}"
+TENANT_GPUS="${TENANT_GPUS:-1}"
+
+# namespace, group bound as edit, display name
+while read -r namespace group display; do
+  echo "[INFO] Provisioning ${namespace} for group ${group}..."
+  "${OCP_LISTER}" projects provision "${namespace}" \
+    --edit-group "${group}" \
+    --display-name "${display}" \
+    --gpus "${TENANT_GPUS}"
+done <<'TENANTS'
+serverless-models serverless-users Serverless models
+acme-inc-models acme-inc-users Acme Inc models
+ethan-group-models ethan-group-users Ethan Group models
+red-hat-models redhat-users Red Hat models
+TENANTS
+
+echo "[INFO] Tenant namespaces provisioned. Sync the model-deployments application next."